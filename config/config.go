@@ -1,12 +1,35 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
 
+// 注册模式
+const (
+	RegistrationOpen     = "open"
+	RegistrationInvite   = "invite-only"
+	RegistrationApproval = "approval-required"
+)
+
+// 评论审核模式
+const (
+	CommentModerationOff       = "off"
+	CommentModerationFirstTime = "first_time"
+	CommentModerationAll       = "all"
+)
+
+// 文章评论模式
+const (
+	CommentsOpen     = "open"     // 允许评论
+	CommentsDisabled = "disabled" // 禁用评论，已有评论不再显示
+	CommentsLocked   = "locked"   // 锁定，已有评论只读
+)
+
 // Config 应用配置
 type Config struct {
 	DBHost     string
@@ -19,6 +42,95 @@ type Config struct {
 	JWTExpiry  string
 	ServerPort string
 	GinMode    string
+
+	// 站点信息，用于订阅源和站点地图中的绝对地址
+	SiteURL         string
+	SiteTitle       string
+	SiteDescription string
+	PostPermalink   string // 文章链接模板，支持 {id} 和 {slug}
+
+	// 订阅源配置
+	FeedFullContent   bool // true 输出全文HTML，false 输出摘要
+	FeedItemLimit     int
+	FeedExcerptLength int
+
+	// robots.txt 中禁止抓取的路径，逗号分隔
+	RobotsDisallow string
+
+	// 文章表态类型，逗号分隔
+	ReactionTypes string
+
+	// 阅读统计配置
+	ViewFlushInterval string // 阅读数写入数据库的间隔
+	ViewDedupWindow   string // 同一访客重复阅读不计数的时间窗口
+
+	// 评论发布后允许作者编辑的时间，如 "15m"，"0" 表示不限制
+	CommentEditWindow string
+	// 评论审核模式: off（不审核）、first_time（首次评论需审核）、all（全部需审核）
+	CommentModeration string
+	// 评论被举报达到该次数后自动隐藏并进入审核队列，0 表示不自动隐藏
+	CommentFlagThreshold int
+	// 新文章的默认评论模式: open、disabled 或 locked
+	CommentDefaultMode string
+	// 文章发布多少天后自动关闭评论，0 表示不自动关闭；文章可单独设置
+	CommentAutoCloseDays int
+
+	// 垃圾内容过滤配置
+	SpamMaxLinks        int     // 评论允许的最大链接数，0 表示不限制
+	SpamKeywords        string  // 屏蔽词，逗号分隔
+	SpamBlocklistFile   string  // 屏蔽词文件，每行一条，/.../ 包裹的行为正则表达式
+	SpamBayesMinDocs    int     // 贝叶斯分类器每类至少学习多少篇后才参与判定，0 表示不启用
+	SpamThreshold       float64 // 得分达到该值判定为垃圾内容
+	SpamReviewThreshold float64 // 得分达到该值需要人工审核
+	AkismetAPIKey       string  // 为空时不启用 Akismet
+	AkismetEndpoint     string
+
+	// 实时推送配置
+	PubSubBroker     string // 消息代理类型，目前支持 memory（单实例）
+	StreamBufferSize int    // 每个连接的事件缓冲数，缓冲区满时断开慢连接
+	StreamHeartbeat  string // 心跳间隔，如 "25s"
+	StreamTicketTTL  string // 一次性票据的有效期，如 "30s"
+
+	// 回收站保留天数，超过后永久删除，0 表示不自动清理
+	TrashRetentionDays int
+
+	// 注册模式: open（开放注册）、invite-only（仅限邀请）、approval-required（需审核）
+	RegistrationMode string
+	// 启动时提升为管理员的用户名，逗号分隔
+	AdminUsernames string
+
+	// 密码策略配置
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+	PasswordRejectCommon  bool
+
+	// 媒体文件配置
+	StorageDriver      string // local 或 s3
+	UploadDir          string
+	UploadBaseURL      string
+	MediaMaxSize       int64  // 单个文件大小上限（字节）
+	MediaUserQuota     int64  // 每个用户的总容量（字节）
+	MediaImageVariants string // 缩略图规格，格式为 名称:最大宽度，逗号分隔
+
+	// S3 兼容存储配置
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+	S3PublicURL string
+
+	// OIDC 第三方登录配置，OIDCIssuer 为空时不启用
+	OIDCProviderName string
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       string
 }
 
 // LoadConfig 加载配置文件
@@ -40,6 +152,97 @@ func LoadConfig() (*Config, error) {
 		JWTExpiry:  getEnv("JWT_EXPIRATION", "720h"),
 		ServerPort: getEnv("SERVER_PORT", "8080"),
 		GinMode:    getEnv("GIN_MODE", "debug"),
+
+		SiteURL:         getEnv("SITE_URL", "http://localhost:8080"),
+		SiteTitle:       getEnv("SITE_TITLE", "Blog"),
+		SiteDescription: getEnv("SITE_DESCRIPTION", ""),
+		PostPermalink:   getEnv("POST_PERMALINK", "/api/posts/by-slug/{slug}"),
+
+		FeedFullContent:   getEnvBool("FEED_FULL_CONTENT", true),
+		FeedItemLimit:     getEnvInt("FEED_ITEM_LIMIT", 20),
+		FeedExcerptLength: getEnvInt("FEED_EXCERPT_LENGTH", 200),
+
+		RobotsDisallow: getEnv("ROBOTS_DISALLOW", "/api/admin/,/api/sessions,/api/media"),
+
+		ReactionTypes: getEnv("REACTION_TYPES", "like,love,laugh,wow,sad,angry"),
+
+		ViewFlushInterval: getEnv("VIEW_FLUSH_INTERVAL", "30s"),
+		ViewDedupWindow:   getEnv("VIEW_DEDUP_WINDOW", "30m"),
+
+		CommentEditWindow:    getEnv("COMMENT_EDIT_WINDOW", "15m"),
+		CommentModeration:    getEnv("COMMENT_MODERATION", CommentModerationOff),
+		CommentFlagThreshold: getEnvInt("COMMENT_FLAG_THRESHOLD", 3),
+		CommentDefaultMode:   getEnv("COMMENT_DEFAULT_MODE", CommentsOpen),
+		CommentAutoCloseDays: getEnvInt("COMMENT_AUTO_CLOSE_DAYS", 0),
+
+		SpamMaxLinks:        getEnvInt("SPAM_MAX_LINKS", 3),
+		SpamKeywords:        getEnv("SPAM_KEYWORDS", ""),
+		SpamBlocklistFile:   getEnv("SPAM_BLOCKLIST_FILE", ""),
+		SpamBayesMinDocs:    getEnvInt("SPAM_BAYES_MIN_DOCS", 20),
+		SpamThreshold:       getEnvFloat("SPAM_THRESHOLD", 0.9),
+		SpamReviewThreshold: getEnvFloat("SPAM_REVIEW_THRESHOLD", 0.5),
+		AkismetAPIKey:       getEnv("AKISMET_API_KEY", ""),
+		AkismetEndpoint:     getEnv("AKISMET_ENDPOINT", "https://rest.akismet.com/1.1"),
+
+		PubSubBroker:     getEnv("PUBSUB_BROKER", "memory"),
+		StreamBufferSize: getEnvInt("STREAM_BUFFER_SIZE", 64),
+		StreamHeartbeat:  getEnv("STREAM_HEARTBEAT", "25s"),
+		StreamTicketTTL:  getEnv("STREAM_TICKET_TTL", "30s"),
+
+		TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+
+		RegistrationMode: getEnv("REGISTRATION_MODE", RegistrationOpen),
+		AdminUsernames:   getEnv("ADMIN_USERNAMES", ""),
+
+		PasswordMinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordRejectCommon:  getEnvBool("PASSWORD_REJECT_COMMON", true),
+
+		StorageDriver:      getEnv("STORAGE_DRIVER", "local"),
+		UploadDir:          getEnv("UPLOAD_DIR", "uploads"),
+		UploadBaseURL:      getEnv("UPLOAD_BASE_URL", "/uploads"),
+		MediaMaxSize:       getEnvInt64("MEDIA_MAX_SIZE", 10<<20),
+		MediaUserQuota:     getEnvInt64("MEDIA_USER_QUOTA", 200<<20),
+		MediaImageVariants: getEnv("MEDIA_IMAGE_VARIANTS", "thumb:320,medium:1024"),
+
+		S3Endpoint:  getEnv("S3_ENDPOINT", ""),
+		S3Region:    getEnv("S3_REGION", ""),
+		S3Bucket:    getEnv("S3_BUCKET", ""),
+		S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:    getEnvBool("S3_USE_SSL", true),
+		S3PublicURL: getEnv("S3_PUBLIC_URL", ""),
+
+		OIDCProviderName: getEnv("OIDC_PROVIDER_NAME", "oidc"),
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:       getEnv("OIDC_SCOPES", "openid,profile,email"),
+	}
+
+	switch config.RegistrationMode {
+	case RegistrationOpen, RegistrationInvite, RegistrationApproval:
+	default:
+		return nil, fmt.Errorf("无效的注册模式: %s", config.RegistrationMode)
+	}
+
+	switch config.CommentModeration {
+	case CommentModerationOff, CommentModerationFirstTime, CommentModerationAll:
+	default:
+		return nil, fmt.Errorf("无效的评论审核模式: %s", config.CommentModeration)
+	}
+
+	switch config.CommentDefaultMode {
+	case CommentsOpen, CommentsDisabled, CommentsLocked:
+	default:
+		return nil, fmt.Errorf("无效的默认评论模式: %s", config.CommentDefaultMode)
+	}
+	if config.CommentAutoCloseDays < 0 {
+		return nil, fmt.Errorf("无效的评论自动关闭天数: %d", config.CommentAutoCloseDays)
 	}
 
 	return config, nil
@@ -53,3 +256,39 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvInt 获取整数类型的环境变量，不存在或格式错误时返回默认值
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt64 获取64位整数类型的环境变量，不存在或格式错误时返回默认值
+func getEnvInt64(key string, defaultValue int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat 获取浮点数类型的环境变量，不存在或格式错误时返回默认值
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvBool 获取布尔类型的环境变量，不存在或格式错误时返回默认值
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,252 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminController 管理控制器
+type AdminController struct {
+	userService   service.UserService
+	inviteService service.InviteService
+}
+
+// NewAdminController 创建管理控制器实例
+func NewAdminController(userService service.UserService, inviteService service.InviteService) *AdminController {
+	return &AdminController{
+		userService:   userService,
+		inviteService: inviteService,
+	}
+}
+
+// CreateInvite 创建邀请码
+func (c *AdminController) CreateInvite(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("创建邀请码时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	var input struct {
+		MaxUses   *int   `json:"max_uses" binding:"omitempty,min=0,max=10000"` // 可使用次数，0 表示不限次数，未提供时为 1
+		ExpiresIn string `json:"expires_in"`                                   // 有效期，如 "72h"，为空表示永不过期
+	}
+
+	// 绑定并验证输入
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		logrus.Warnf("创建邀请码输入验证失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	maxUses := 1
+	if input.MaxUses != nil {
+		maxUses = *input.MaxUses
+	}
+
+	var validFor time.Duration
+	if input.ExpiresIn != "" {
+		d, err := time.ParseDuration(input.ExpiresIn)
+		if err != nil || d <= 0 {
+			logrus.Warnf("无效的邀请码有效期: %s", input.ExpiresIn)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的有效期"})
+			return
+		}
+		validFor = d
+	}
+
+	// 创建邀请码
+	invite, err := c.inviteService.CreateInvite(userID.(uint), maxUses, validFor)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "创建邀请码失败: " + err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "邀请码创建成功",
+		"invite":  invite,
+	})
+}
+
+// ListInvites 获取邀请码列表
+func (c *AdminController) ListInvites(ctx *gin.Context) {
+	// 获取分页参数
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	// 获取邀请码列表
+	invites, total, err := c.inviteService.ListInvites(page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取邀请码列表失败: " + err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"invites": invites,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+			"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// DeleteInvite 作废邀请码
+func (c *AdminController) DeleteInvite(ctx *gin.Context) {
+	// 获取邀请码ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的邀请码ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的邀请码ID"})
+		return
+	}
+
+	// 作废邀请码
+	if err := c.inviteService.DeleteInvite(uint(id)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "邀请码不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": "邀请码已作废"})
+}
+
+// ListPendingUsers 获取待审核用户列表
+func (c *AdminController) ListPendingUsers(ctx *gin.Context) {
+	// 获取分页参数
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	// 获取待审核用户
+	users, total, err := c.userService.ListPendingUsers(page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取待审核用户失败: " + err.Error()})
+		return
+	}
+
+	// 处理用户数据
+	var userList []gin.H
+	for _, user := range users {
+		userList = append(userList, gin.H{
+			"id":         user.ID,
+			"username":   user.Username,
+			"email":      user.Email,
+			"created_at": user.CreatedAt,
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"users": userList,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+			"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// ApproveUser 审核通过用户
+func (c *AdminController) ApproveUser(ctx *gin.Context) {
+	c.reviewUser(ctx, c.userService.ApproveUser, "用户已审核通过")
+}
+
+// RejectUser 拒绝用户注册
+func (c *AdminController) RejectUser(ctx *gin.Context) {
+	c.reviewUser(ctx, c.userService.RejectUser, "用户注册已拒绝")
+}
+
+// reviewUser 处理用户审核请求
+func (c *AdminController) reviewUser(ctx *gin.Context, review func(id uint) error, message string) {
+	// 获取用户ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的用户ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	// 审核用户
+	if err := review(uint(id)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "用户不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "用户不在待审核状态" {
+			statusCode = http.StatusConflict
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// SetUserRole 设置用户角色
+func (c *AdminController) SetUserRole(ctx *gin.Context) {
+	// 获取用户ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的用户ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var input struct {
+		Role string `json:"role" binding:"required,oneof=user moderator admin"`
+	}
+
+	// 绑定并验证输入
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		logrus.Warnf("设置角色输入验证失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	// 设置角色
+	if err := c.userService.SetUserRole(uint(id), input.Role); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "用户不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "无效的角色" {
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": "角色设置成功"})
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AnalyticsController 阅读统计控制器
+type AnalyticsController struct {
+	viewService service.ViewService
+}
+
+// NewAnalyticsController 创建阅读统计控制器实例
+func NewAnalyticsController(viewService service.ViewService) *AnalyticsController {
+	return &AnalyticsController{
+		viewService: viewService,
+	}
+}
+
+// Dashboard 获取当前用户所有文章的阅读统计
+func (c *AnalyticsController) Dashboard(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取阅读统计时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	analytics, err := c.viewService.AuthorAnalytics(userID.(uint), analyticsDays(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取阅读统计失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+// PostAnalytics 获取单篇文章的阅读统计
+func (c *AnalyticsController) PostAnalytics(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取文章阅读统计时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	analytics, err := c.viewService.PostAnalytics(uint(id), userID.(uint), analyticsDays(ctx))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "文章不存在":
+			statusCode = http.StatusNotFound
+		case "无权查看该文章的统计":
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
+
+// analyticsDays 解析统计天数，默认30天，最多365天
+func analyticsDays(ctx *gin.Context) int {
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		days = 30
+	}
+	return days
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockController 屏蔽与隐藏控制器
+type BlockController struct {
+	blockService service.BlockService
+}
+
+// NewBlockController 创建屏蔽与隐藏控制器实例
+func NewBlockController(blockService service.BlockService) *BlockController {
+	return &BlockController{
+		blockService: blockService,
+	}
+}
+
+// Block 屏蔽用户
+func (c *BlockController) Block(ctx *gin.Context) {
+	c.update(ctx, c.blockService.Block, "已屏蔽该用户")
+}
+
+// Unblock 取消屏蔽用户
+func (c *BlockController) Unblock(ctx *gin.Context) {
+	c.update(ctx, c.blockService.Unblock, "已取消屏蔽")
+}
+
+// Mute 隐藏用户
+func (c *BlockController) Mute(ctx *gin.Context) {
+	c.update(ctx, c.blockService.Mute, "已隐藏该用户")
+}
+
+// Unmute 取消隐藏用户
+func (c *BlockController) Unmute(ctx *gin.Context) {
+	c.update(ctx, c.blockService.Unmute, "已取消隐藏")
+}
+
+// ListBlocked 获取当前用户屏蔽的用户列表（游标分页）
+func (c *BlockController) ListBlocked(ctx *gin.Context) {
+	pageSize, cursor, withTotal := blockListQuery(ctx)
+	blocks, page, err := c.blockService.ListBlocked(ctx.GetUint("userID"), cursor, pageSize, withTotal)
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取屏蔽列表失败: " + err.Error()})
+		return
+	}
+
+	var userList []gin.H
+	for _, block := range blocks {
+		userList = append(userList, gin.H{
+			"id":         block.BlockedID,
+			"username":   block.Blocked.Username,
+			"blocked_at": block.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"users":      userList,
+		"pagination": cursorPagination(page, pageSize),
+	})
+}
+
+// ListMuted 获取当前用户隐藏的用户列表（游标分页）
+func (c *BlockController) ListMuted(ctx *gin.Context) {
+	pageSize, cursor, withTotal := blockListQuery(ctx)
+	mutes, page, err := c.blockService.ListMuted(ctx.GetUint("userID"), cursor, pageSize, withTotal)
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取隐藏列表失败: " + err.Error()})
+		return
+	}
+
+	var userList []gin.H
+	for _, mute := range mutes {
+		userList = append(userList, gin.H{
+			"id":       mute.MutedID,
+			"username": mute.Muted.Username,
+			"muted_at": mute.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"users":      userList,
+		"pagination": cursorPagination(page, pageSize),
+	})
+}
+
+// update 处理屏蔽、隐藏及取消请求
+func (c *BlockController) update(ctx *gin.Context, action func(userID, targetID uint) error, message string) {
+	id, ok := userIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := action(ctx.GetUint("userID"), id); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "用户不存在":
+			statusCode = http.StatusNotFound
+		case "不能屏蔽自己", "不能隐藏自己":
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// blockListQuery 解析屏蔽和隐藏列表的分页参数
+func blockListQuery(ctx *gin.Context) (pageSize int, cursor string, withTotal bool) {
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	cursor, _, withTotal = cursorQuery(ctx)
+	return pageSize, cursor, withTotal
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"blog-backend/model"
 	"blog-backend/service"
 	"net/http"
 	"strconv"
@@ -12,12 +13,14 @@ import (
 // CommentController 评论控制器
 type CommentController struct {
 	commentService service.CommentService
+	postService    service.PostService
 }
 
 // NewCommentController 创建评论控制器实例
-func NewCommentController(commentService service.CommentService) *CommentController {
+func NewCommentController(commentService service.CommentService, postService service.PostService) *CommentController {
 	return &CommentController{
 		commentService: commentService,
+		postService:    postService,
 	}
 }
 
@@ -41,7 +44,8 @@ func (c *CommentController) CreateComment(ctx *gin.Context) {
 	}
 
 	var input struct {
-		Content string `json:"content" binding:"required,min=1,max=500"`
+		Content  string `json:"content" binding:"required,min=1,max=500"`
+		ParentID uint   `json:"parent_id"` // 回复的评论ID，可选
 	}
 
 	// 绑定并验证输入
@@ -51,26 +55,47 @@ func (c *CommentController) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	// 检查文章访问权限
+	if !checkPostAccess(ctx, c.postService, uint(postID)) {
+		return
+	}
+
 	// 创建评论
-	comment, err := c.commentService.CreateComment(input.Content, userID.(uint), uint(postID))
+	comment, err := c.commentService.CreateComment(input.Content, userID.(uint), uint(postID), input.ParentID, ctx.Request.UserAgent(), ctx.ClientIP())
 	if err != nil {
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "文章不存在" {
 			statusCode = http.StatusNotFound
+		} else if err.Error() == "回复的评论不存在" {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "对方已将你屏蔽" {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "评论已关闭" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "COMMENTS_CLOSED"})
+			return
 		}
 		ctx.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 返回结果
+	message := "评论创建成功"
+	if comment.Status == model.CommentStatusPending || comment.Status == model.CommentStatusSpam {
+		message = "评论已提交，等待审核"
+	}
 	ctx.JSON(http.StatusCreated, gin.H{
-		"message": "评论创建成功",
+		"message": message,
 		"comment": gin.H{
-			"id":         comment.ID,
-			"content":    comment.Content,
-			"user_id":    comment.UserID,
-			"post_id":    comment.PostID,
-			"created_at": comment.CreatedAt,
+			"id":           comment.ID,
+			"content":      comment.Content,
+			"content_html": comment.ContentHTML,
+			"parent_id":    comment.ParentID,
+			"user_id":      comment.UserID,
+			"post_id":      comment.PostID,
+			"status":       comment.Status,
+			"created_at":   comment.CreatedAt,
+			"edited":       comment.EditedAt != nil,
+			"edited_at":    comment.EditedAt,
 		},
 	})
 }
@@ -97,14 +122,27 @@ func (c *CommentController) GetPostComments(ctx *gin.Context) {
 		pageSize = 20
 	}
 
-	// 获取评论列表
-	comments, total, err := c.commentService.GetPostComments(uint(postID), page, pageSize)
-	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "文章不存在" {
-			statusCode = http.StatusNotFound
+	// 检查文章访问权限
+	if !checkPostAccess(ctx, c.postService, uint(postID)) {
+		return
+	}
+
+	// 获取评论列表，带 cursor 参数时使用游标分页
+	var comments []model.Comment
+	var pagination gin.H
+	if cursor, ok, withTotal := cursorQuery(ctx); ok {
+		var cursorPage *service.CursorPage
+		comments, cursorPage, err = c.commentService.GetPostCommentsByCursor(uint(postID), viewerID(ctx), cursor, pageSize, withTotal)
+		if err == nil {
+			pagination = cursorPagination(cursorPage, pageSize)
 		}
-		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+	} else {
+		var total int64
+		comments, total, err = c.commentService.GetPostComments(uint(postID), viewerID(ctx), page, pageSize)
+		pagination = offsetPagination(total, page, pageSize)
+	}
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -112,22 +150,90 @@ func (c *CommentController) GetPostComments(ctx *gin.Context) {
 	var commentList []gin.H
 	for _, comment := range comments {
 		commentList = append(commentList, gin.H{
-			"id":         comment.ID,
-			"content":    comment.Content,
-			"user_id":    comment.UserID,
-			"username":   comment.User.Username,
-			"created_at": comment.CreatedAt,
+			"id":           comment.ID,
+			"content":      comment.Content,
+			"content_html": comment.ContentHTML,
+			"parent_id":    comment.ParentID,
+			"user_id":      comment.UserID,
+			"username":     comment.User.Username,
+			"created_at":   comment.CreatedAt,
+			"edited":       comment.EditedAt != nil,
+			"edited_at":    comment.EditedAt,
 		})
 	}
 
 	// 返回结果
 	ctx.JSON(http.StatusOK, gin.H{
 		"comments":   commentList,
-		"pagination": gin.H{
-			"total":     total,
-			"page":      page,
-			"page_size": pageSize,
-			"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
+		"pagination": pagination,
+	})
+}
+
+// PatchComment 部分更新评论，请求体为 JSON Merge Patch（RFC 7396）
+func (c *CommentController) PatchComment(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("更新评论时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取评论ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的评论ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	// 解析并逐字段验证
+	patch, ok := bindMergePatch(ctx, "content")
+	if !ok {
+		return
+	}
+	commentPatch := service.CommentPatch{
+		Content: patch.stringField("content", 1, 500, false),
+	}
+	if !patch.valid(ctx) {
+		return
+	}
+
+	// 更新评论
+	comment, err := c.commentService.PatchComment(uint(id), userID.(uint), commentPatch, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "评论不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "没有权限更新此评论" || err.Error() == "评论已超过可编辑时间" {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "评论已关闭" {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "COMMENTS_CLOSED"})
+			return
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	message := "评论更新成功"
+	if comment.Status == model.CommentStatusPending || comment.Status == model.CommentStatusSpam {
+		message = "评论已提交，等待审核"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"comment": gin.H{
+			"id":           comment.ID,
+			"content":      comment.Content,
+			"content_html": comment.ContentHTML,
+			"parent_id":    comment.ParentID,
+			"user_id":      comment.UserID,
+			"post_id":      comment.PostID,
+			"status":       comment.Status,
+			"created_at":   comment.CreatedAt,
+			"edited":       comment.EditedAt != nil,
+			"edited_at":    comment.EditedAt,
 		},
 	})
 }
@@ -167,3 +273,27 @@ func (c *CommentController) DeleteComment(ctx *gin.Context) {
 	// 返回结果
 	ctx.JSON(http.StatusOK, gin.H{"message": "评论删除成功"})
 }
+
+// ListRevisions 获取评论的历史版本（版主和管理员）
+func (c *CommentController) ListRevisions(ctx *gin.Context) {
+	// 获取评论ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的评论ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	revisions, err := c.commentService.ListRevisions(uint(id))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "评论不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
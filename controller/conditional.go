@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeConditional 写入响应并支持条件请求（ETag / Last-Modified），未变化时返回304
+func writeConditional(ctx *gin.Context, contentType string, body []byte, lastModified time.Time) {
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(ctx, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, body)
+}
+
+// notModified 判断客户端缓存是否仍然有效，If-None-Match 优先于 If-Modified-Since
+func notModified(ctx *gin.Context, etag string, lastModified time.Time) bool {
+	if inm := ctx.GetHeader("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	if ims := ctx.GetHeader("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// etagMatches 判断 If-None-Match / If-Match 头是否包含指定 ETag（弱比较）
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// versionETag 生成带版本号的 ETag，格式为 "v<版本号>-<内容摘要>"，body 为空时省略摘要
+func versionETag(version uint, body []byte) string {
+	tag := "v" + strconv.FormatUint(uint64(version), 10)
+	if len(body) > 0 {
+		sum := sha1.Sum(body)
+		tag += "-" + hex.EncodeToString(sum[:6])
+	}
+	return `"` + tag + `"`
+}
+
+// ifMatchVersion 从 If-Match 头中解析期望的版本号，只比较版本号部分；
+// "*" 表示匹配任意版本，返回 0
+func ifMatchVersion(header string) (uint, bool) {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return 0, true
+		}
+		tag := strings.Trim(candidate, `"`)
+		if !strings.HasPrefix(tag, "v") || strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		tag = strings.TrimPrefix(tag, "v")
+		if i := strings.IndexByte(tag, '-'); i >= 0 {
+			tag = tag[:i]
+		}
+		if version, err := strconv.ParseUint(tag, 10, 32); err == nil && version > 0 {
+			return uint(version), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/feeds"
+	"github.com/sirupsen/logrus"
+)
+
+// feedContentTypes 订阅源格式对应的内容类型
+var feedContentTypes = map[string]string{
+	".rss":  "application/rss+xml; charset=utf-8",
+	".atom": "application/atom+xml; charset=utf-8",
+	".json": "application/feed+json; charset=utf-8",
+}
+
+// FeedController 订阅源控制器
+type FeedController struct {
+	feedService service.FeedService
+}
+
+// NewFeedController 创建订阅源控制器实例
+func NewFeedController(feedService service.FeedService) *FeedController {
+	return &FeedController{
+		feedService: feedService,
+	}
+}
+
+// SiteFeed 全站订阅源，格式由路由后缀决定（.rss / .atom / .json）
+func (c *FeedController) SiteFeed(ctx *gin.Context) {
+	feed, err := c.feedService.SiteFeed()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取订阅源失败: " + err.Error()})
+		return
+	}
+
+	c.render(ctx, feed)
+}
+
+// AuthorFeed 作者订阅源
+func (c *FeedController) AuthorFeed(ctx *gin.Context) {
+	// 获取用户ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的用户ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	feed, err := c.feedService.AuthorFeed(uint(id))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "用户不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.render(ctx, feed)
+}
+
+// render 按路由后缀输出订阅源
+func (c *FeedController) render(ctx *gin.Context, feed *feeds.Feed) {
+	ext := path.Ext(ctx.FullPath())
+
+	var body string
+	var err error
+	switch ext {
+	case ".rss":
+		body, err = feed.ToRss()
+	case ".atom":
+		body, err = feed.ToAtom()
+	case ".json":
+		body, err = feed.ToJSON()
+	default:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "不支持的订阅格式"})
+		return
+	}
+	if err != nil {
+		logrus.Errorf("生成订阅源失败: %v", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "生成订阅源失败"})
+		return
+	}
+
+	writeConditional(ctx, feedContentTypes[ext], []byte(body), feed.Updated)
+}
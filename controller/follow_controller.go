@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"blog-backend/model"
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// FollowController 关注控制器
+type FollowController struct {
+	followService   service.FollowService
+	reactionService service.ReactionService
+}
+
+// NewFollowController 创建关注控制器实例
+func NewFollowController(followService service.FollowService, reactionService service.ReactionService) *FollowController {
+	return &FollowController{
+		followService:   followService,
+		reactionService: reactionService,
+	}
+}
+
+// Follow 关注用户
+func (c *FollowController) Follow(ctx *gin.Context) {
+	id, ok := userIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.followService.Follow(ctx.GetUint("userID"), id); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "用户不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "不能关注自己" {
+			statusCode = http.StatusBadRequest
+		} else if err.Error() == "对方已将你屏蔽" {
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "关注成功"})
+}
+
+// Unfollow 取消关注用户
+func (c *FollowController) Unfollow(ctx *gin.Context) {
+	id, ok := userIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.followService.Unfollow(ctx.GetUint("userID"), id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "取消关注失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "已取消关注"})
+}
+
+// ListFollowers 获取用户的粉丝列表（游标分页）及关注统计
+func (c *FollowController) ListFollowers(ctx *gin.Context) {
+	c.listFollows(ctx, "followers", c.followService.ListFollowers, func(f *model.Follow) *model.User {
+		return &f.Follower
+	})
+}
+
+// ListFollowing 获取用户关注的用户列表（游标分页）及关注统计
+func (c *FollowController) ListFollowing(ctx *gin.Context) {
+	c.listFollows(ctx, "following", c.followService.ListFollowing, func(f *model.Follow) *model.User {
+		return &f.Followee
+	})
+}
+
+// Feed 获取当前用户关注的作者发布的文章（游标分页）
+func (c *FollowController) Feed(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取关注动态时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	cursor, _, withTotal := cursorQuery(ctx)
+
+	// 获取关注动态
+	posts, page, err := c.followService.Feed(userID.(uint), cursor, pageSize, withTotal)
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取关注动态失败: " + err.Error()})
+		return
+	}
+
+	// 获取文章互动统计
+	postIDs := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	stats, err := c.reactionService.GetPostStats(postIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取关注动态失败: " + err.Error()})
+		return
+	}
+
+	// 处理文章数据
+	var postList []gin.H
+	for _, post := range posts {
+		postList = append(postList, gin.H{
+			"id":             post.ID,
+			"title":          post.Title,
+			"slug":           post.Slug,
+			"visibility":     post.Visibility,
+			"user_id":        post.UserID,
+			"username":       post.User.Username,
+			"created_at":     post.CreatedAt,
+			"updated_at":     post.UpdatedAt,
+			"reactions":      stats[post.ID].Reactions,
+			"bookmark_count": stats[post.ID].BookmarkCount,
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"posts":      postList,
+		"pagination": cursorPagination(page, pageSize),
+	})
+}
+
+// listFollows 处理粉丝列表和关注列表请求，user 返回列表中展示的一方
+func (c *FollowController) listFollows(
+	ctx *gin.Context,
+	key string,
+	list func(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Follow, *service.CursorPage, error),
+	user func(f *model.Follow) *model.User,
+) {
+	id, ok := userIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	cursor, _, withTotal := cursorQuery(ctx)
+
+	// 获取列表
+	follows, page, err := list(id, cursor, pageSize, withTotal)
+	if err != nil {
+		statusCode := listErrorStatus(err)
+		if err.Error() == "用户不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+	stats, err := c.followService.GetFollowStats(id, viewerID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 处理用户数据
+	var userList []gin.H
+	for i := range follows {
+		u := user(&follows[i])
+		userList = append(userList, gin.H{
+			"id":          u.ID,
+			"username":    u.Username,
+			"followed_at": follows[i].CreatedAt,
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		key:               userList,
+		"follower_count":  stats.FollowerCount,
+		"following_count": stats.FollowingCount,
+		"is_following":    stats.Following,
+		"pagination":      cursorPagination(page, pageSize),
+	})
+}
+
+// userIDParam 解析路径中的用户ID，无效时直接写入错误响应
+func userIDParam(ctx *gin.Context) (uint, bool) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的用户ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
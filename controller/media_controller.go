@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"blog-backend/config"
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// MediaController 媒体文件控制器
+type MediaController struct {
+	mediaService service.MediaService
+	cfg          *config.Config
+}
+
+// NewMediaController 创建媒体文件控制器实例
+func NewMediaController(mediaService service.MediaService, cfg *config.Config) *MediaController {
+	return &MediaController{
+		mediaService: mediaService,
+		cfg:          cfg,
+	}
+}
+
+// Upload 上传文件（multipart/form-data，字段名 file）
+func (c *MediaController) Upload(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("上传文件时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 限制请求体大小，预留表单字段的空间
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.cfg.MediaMaxSize+1<<20)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		logrus.Warnf("获取上传文件失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的上传文件"})
+		return
+	}
+	if fileHeader.Size > c.cfg.MediaMaxSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "文件大小超过限制"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logrus.Errorf("打开上传文件失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的上传文件"})
+		return
+	}
+	defer file.Close()
+
+	// 上传文件
+	media, err := c.mediaService.Upload(ctx.Request.Context(), userID.(uint), fileHeader.Filename, file)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "文件大小超过限制":
+			statusCode = http.StatusRequestEntityTooLarge
+		case "不支持的文件类型":
+			statusCode = http.StatusUnsupportedMediaType
+		case "存储空间不足":
+			statusCode = http.StatusForbidden
+		case "文件为空", "无效的图片文件":
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "文件上传成功",
+		"media":   media,
+	})
+}
+
+// ListMedia 获取当前用户上传的文件列表
+func (c *MediaController) ListMedia(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取文件列表时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取分页参数
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	// 获取文件列表
+	media, total, used, err := c.mediaService.ListUserMedia(userID.(uint), page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取文件列表失败: " + err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"media": media,
+		"quota": gin.H{
+			"used":  used,
+			"limit": c.cfg.MediaUserQuota,
+		},
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+			"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// GetMedia 获取文件信息及引用它的文章
+func (c *MediaController) GetMedia(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取文件信息时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文件ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文件ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文件ID"})
+		return
+	}
+
+	// 获取文件信息
+	media, postIDs, err := c.mediaService.GetMediaByID(uint(id))
+	if err != nil || media.UserID != userID.(uint) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "文件不存在"})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"media":    media,
+		"post_ids": postIDs,
+	})
+}
+
+// DeleteMedia 删除文件
+func (c *MediaController) DeleteMedia(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("删除文件时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文件ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文件ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文件ID"})
+		return
+	}
+
+	// 删除文件
+	err = c.mediaService.DeleteMedia(ctx.Request.Context(), uint(id), userID.(uint))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "文件不存在":
+			statusCode = http.StatusNotFound
+		case "没有权限删除此文件":
+			statusCode = http.StatusForbidden
+		case "文件正被文章引用":
+			statusCode = http.StatusConflict
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": "文件删除成功"})
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ModerationController 评论审核控制器
+type ModerationController struct {
+	moderationService service.ModerationService
+}
+
+// NewModerationController 创建评论审核控制器实例
+func NewModerationController(moderationService service.ModerationService) *ModerationController {
+	return &ModerationController{
+		moderationService: moderationService,
+	}
+}
+
+// FlagComment 举报评论
+func (c *ModerationController) FlagComment(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("举报评论时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取评论ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的评论ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	var input struct {
+		Reason string `json:"reason" binding:"required,min=1,max=500"`
+	}
+
+	// 绑定并验证输入
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		logrus.Warnf("举报评论输入验证失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	// 举报评论
+	if err := c.moderationService.FlagComment(uint(id), userID.(uint), input.Reason); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "评论不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "不能举报自己的评论" {
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": "举报已提交"})
+}
+
+// ListQueue 获取评论审核队列，status 为 pending（默认）、flagged 或 spam
+func (c *ModerationController) ListQueue(ctx *gin.Context) {
+	// 获取分页参数
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	// 获取审核队列
+	comments, total, err := c.moderationService.ListQueue(ctx.DefaultQuery("status", service.QueuePending), page, pageSize)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "无效的审核队列" {
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 处理评论数据
+	var commentList []gin.H
+	for _, comment := range comments {
+		var flags []gin.H
+		for _, flag := range comment.Flags {
+			flags = append(flags, gin.H{
+				"user_id":    flag.UserID,
+				"reason":     flag.Reason,
+				"created_at": flag.CreatedAt,
+			})
+		}
+		commentList = append(commentList, gin.H{
+			"id":           comment.ID,
+			"content":      comment.Content,
+			"content_html": comment.ContentHTML,
+			"post_id":      comment.PostID,
+			"user_id":      comment.UserID,
+			"username":     comment.User.Username,
+			"status":       comment.Status,
+			"created_at":   comment.CreatedAt,
+			"flag_count":   len(comment.Flags),
+			"flags":        flags,
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"comments":   commentList,
+		"pagination": offsetPagination(total, page, pageSize),
+	})
+}
+
+// ApproveComment 审核通过评论
+func (c *ModerationController) ApproveComment(ctx *gin.Context) {
+	c.moderate(ctx, c.moderationService.ApproveComment, "评论已审核通过")
+}
+
+// RejectComment 拒绝评论
+func (c *ModerationController) RejectComment(ctx *gin.Context) {
+	c.moderate(ctx, c.moderationService.RejectComment, "评论已拒绝")
+}
+
+// MarkSpam 将评论标记为垃圾评论
+func (c *ModerationController) MarkSpam(ctx *gin.Context) {
+	c.moderate(ctx, c.moderationService.MarkSpam, "评论已标记为垃圾评论")
+}
+
+// moderate 处理评论审核请求
+func (c *ModerationController) moderate(ctx *gin.Context, action func(id, moderatorID uint) error, message string) {
+	// 获取评论ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的评论ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的评论ID"})
+		return
+	}
+
+	// 审核评论
+	if err := action(uint(id), ctx.GetUint("userID")); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "评论不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
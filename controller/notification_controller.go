@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// NotificationController 站内通知控制器
+type NotificationController struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationController 创建站内通知控制器实例
+func NewNotificationController(notificationService service.NotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+	}
+}
+
+// ListNotifications 获取当前用户的通知列表（游标分页），unread=true 时只返回未读通知
+func (c *NotificationController) ListNotifications(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取通知列表时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	cursor, _, withTotal := cursorQuery(ctx)
+	unreadOnly := ctx.Query("unread") == "true" || ctx.Query("unread") == "1"
+
+	// 获取通知列表
+	notifications, page, err := c.notificationService.ListNotifications(userID.(uint), unreadOnly, cursor, pageSize, withTotal)
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取通知列表失败: " + err.Error()})
+		return
+	}
+	unread, err := c.notificationService.UnreadCount(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取通知列表失败: " + err.Error()})
+		return
+	}
+
+	// 处理通知数据
+	var notificationList []gin.H
+	for _, n := range notifications {
+		notificationList = append(notificationList, gin.H{
+			"id":         n.ID,
+			"type":       n.Type,
+			"actor_id":   n.ActorID,
+			"actor":      n.Actor.Username,
+			"post_id":    n.PostID,
+			"comment_id": n.CommentID,
+			"read":       n.ReadAt != nil,
+			"created_at": n.CreatedAt,
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"notifications": notificationList,
+		"unread_count":  unread,
+		"pagination":    cursorPagination(page, pageSize),
+	})
+}
+
+// UnreadCount 获取当前用户的未读通知数
+func (c *NotificationController) UnreadCount(ctx *gin.Context) {
+	count, err := c.notificationService.UnreadCount(ctx.GetUint("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取未读通知数失败: " + err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+// MarkRead 将通知标记为已读
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	// 获取通知ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的通知ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的通知ID"})
+		return
+	}
+
+	if err := c.notificationService.MarkRead(uint(id), ctx.GetUint("userID")); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "通知不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "通知已标记为已读"})
+}
+
+// MarkAllRead 将当前用户的所有通知标记为已读
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	count, err := c.notificationService.MarkAllRead(ctx.GetUint("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "标记通知已读失败: " + err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "所有通知已标记为已读", "count": count})
+}
+
+// GetPreferences 获取当前用户的通知偏好
+func (c *NotificationController) GetPreferences(ctx *gin.Context) {
+	prefs, err := c.notificationService.GetPreferences(ctx.GetUint("userID"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取通知偏好失败: " + err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// UpdatePreferences 更新当前用户的通知偏好，只更新提供的字段
+func (c *NotificationController) UpdatePreferences(ctx *gin.Context) {
+	var input service.NotificationPreferences
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		logrus.Warnf("更新通知偏好输入验证失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	prefs, err := c.notificationService.UpdatePreferences(ctx.GetUint("userID"), input)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "更新通知偏好失败: " + err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "通知偏好已更新", "preferences": prefs})
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"blog-backend/config"
+	"blog-backend/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// OAuthController 第三方登录控制器
+type OAuthController struct {
+	oauthService   service.OAuthService
+	sessionService service.SessionService
+	cfg            *config.Config
+}
+
+// oauthBindingCookie 保存授权请求绑定值的 Cookie，回调时校验发起登录的是同一个浏览器
+const oauthBindingCookie = "oauth_binding"
+
+// NewOAuthController 创建第三方登录控制器实例
+func NewOAuthController(oauthService service.OAuthService, sessionService service.SessionService, cfg *config.Config) *OAuthController {
+	return &OAuthController{
+		oauthService:   oauthService,
+		sessionService: sessionService,
+		cfg:            cfg,
+	}
+}
+
+// Login 跳转到身份提供方的授权页面
+func (c *OAuthController) Login(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	authURL, binding, err := c.oauthService.AuthCodeURL(ctx.Request.Context(), provider)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "不支持的登录方式" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "身份提供方不可用" {
+			statusCode = http.StatusBadGateway
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.setBindingCookie(ctx, binding, int(service.OAuthStateTTL.Seconds()))
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 处理身份提供方的授权回调
+func (c *OAuthController) Callback(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	// 身份提供方返回的错误
+	if errCode := ctx.Query("error"); errCode != "" {
+		logrus.Warnf("第三方登录被拒绝: %s - %s", errCode, ctx.Query("error_description"))
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "第三方登录失败: " + errCode})
+		return
+	}
+
+	state := ctx.Query("state")
+	code := ctx.Query("code")
+	if state == "" || code == "" {
+		logrus.Warn("第三方登录回调缺少state或code")
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的回调参数"})
+		return
+	}
+
+	// 校验授权结果并获取本地用户，绑定值只能使用一次
+	binding, _ := ctx.Cookie(oauthBindingCookie)
+	c.setBindingCookie(ctx, "", -1)
+	user, err := c.oauthService.HandleCallback(ctx.Request.Context(), provider, state, code, binding)
+	if err != nil {
+		statusCode := http.StatusUnauthorized
+		switch err.Error() {
+		case "不支持的登录方式":
+			statusCode = http.StatusNotFound
+		case "身份提供方不可用":
+			statusCode = http.StatusBadGateway
+		case "无效或已过期的登录请求":
+			statusCode = http.StatusBadRequest
+		case "邮箱已存在", "身份提供方未返回邮箱":
+			statusCode = http.StatusConflict
+		case "当前仅限邀请注册", "账号正在等待审核", "账号审核未通过":
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 创建会话并生成JWT令牌
+	token, ok := issueLoginToken(ctx, c.sessionService, c.cfg, user.ID)
+	if !ok {
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "登录成功",
+		"token":   token,
+		"user": gin.H{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+		},
+	})
+}
+
+// setBindingCookie 写入授权请求绑定值，maxAge 小于 0 时删除。
+// 使用 SameSite=Lax，身份提供方跳转回来的顶层 GET 请求仍会携带
+func (c *OAuthController) setBindingCookie(ctx *gin.Context, value string, maxAge int) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	secure := strings.HasPrefix(c.cfg.OIDCRedirectURL, "https://")
+	ctx.SetCookie(oauthBindingCookie, value, maxAge, "/api/oauth", "", secure, true)
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cursorQuery 解析游标分页参数，请求中带有 cursor 参数（可为空，表示第一页）时启用游标分页
+func cursorQuery(ctx *gin.Context) (cursor string, enabled, withTotal bool) {
+	cursor, enabled = ctx.GetQuery("cursor")
+	withTotal = ctx.Query("with_total") == "true" || ctx.Query("with_total") == "1"
+	return cursor, enabled, withTotal
+}
+
+// cursorPagination 生成游标分页信息
+func cursorPagination(page *service.CursorPage, pageSize int) gin.H {
+	pagination := gin.H{
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+		"page_size":   pageSize,
+	}
+	if page.Total != nil {
+		pagination["total"] = *page.Total
+	}
+	return pagination
+}
+
+// offsetPagination 生成页码分页信息
+func offsetPagination(total int64, page, pageSize int) gin.H {
+	return gin.H{
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+		"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
+	}
+}
+
+// listErrorStatus 列表查询错误对应的HTTP状态码
+func listErrorStatus(err error) int {
+	switch err.Error() {
+	case "无效的分页游标":
+		return http.StatusBadRequest
+	case "文章不存在":
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mergePatch JSON Merge Patch（RFC 7396）请求体，字段不存在表示不修改，null 表示移除
+type mergePatch struct {
+	fields map[string]json.RawMessage
+	errors map[string]string
+}
+
+// bindMergePatch 解析 JSON Merge Patch 请求体，只接受 allowed 中的字段。
+// 解析失败时写入错误响应并返回 false
+func bindMergePatch(ctx *gin.Context, allowed ...string) (*mergePatch, bool) {
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "请求体必须为 application/merge-patch+json"})
+		return nil, false
+	}
+
+	patch := &mergePatch{errors: map[string]string{}}
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&patch.fields); err != nil || patch.fields == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "请求体必须为 JSON 对象"})
+		return nil, false
+	}
+
+	for name := range patch.fields {
+		known := false
+		for _, field := range allowed {
+			if name == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			patch.errors[name] = "不支持修改该字段"
+		}
+	}
+	return patch, true
+}
+
+// stringField 读取字符串字段并按字符数校验长度，maxLen 为 0 表示不限制。
+// 字段不存在时返回 nil；值为 null 时，allowNull 为 true 返回指向空字符串的指针，否则记为错误
+func (p *mergePatch) stringField(name string, minLen, maxLen int, allowNull bool) *string {
+	raw, ok := p.fields[name]
+	if !ok {
+		return nil
+	}
+	if string(raw) == "null" {
+		if !allowNull {
+			p.errors[name] = "不能为空"
+			return nil
+		}
+		empty := ""
+		return &empty
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		p.errors[name] = "必须为字符串"
+		return nil
+	}
+	length := utf8.RuneCountInString(value)
+	if length < minLen {
+		p.errors[name] = "长度不能少于 " + strconv.Itoa(minLen) + " 个字符"
+		return nil
+	}
+	if maxLen > 0 && length > maxLen {
+		p.errors[name] = "长度不能超过 " + strconv.Itoa(maxLen) + " 个字符"
+		return nil
+	}
+	return &value
+}
+
+// oneOfField 读取取值限定在 options 中的字符串字段，值为 null 时返回指向空字符串的指针
+func (p *mergePatch) oneOfField(name string, options ...string) *string {
+	value := p.stringField(name, 0, 0, true)
+	if value == nil || *value == "" {
+		return value
+	}
+	for _, option := range options {
+		if *value == option {
+			return value
+		}
+	}
+	p.errors[name] = "取值必须为 " + strings.Join(options, "、") + " 之一"
+	return nil
+}
+
+// valid 检查是否存在字段错误，有错误时写入 400 响应并返回 false
+func (p *mergePatch) valid(ctx *gin.Context) bool {
+	if len(p.errors) == 0 {
+		return true
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据", "fields": p.errors})
+	return false
+}
@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"blog-backend/model"
 	"blog-backend/service"
+	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -11,13 +14,17 @@ import (
 
 // PostController 文章控制器
 type PostController struct {
-	postService service.PostService
+	postService     service.PostService
+	reactionService service.ReactionService
+	viewService     service.ViewService
 }
 
 // NewPostController 创建文章控制器实例
-func NewPostController(postService service.PostService) *PostController {
+func NewPostController(postService service.PostService, reactionService service.ReactionService, viewService service.ViewService) *PostController {
 	return &PostController{
-		postService: postService,
+		postService:     postService,
+		reactionService: reactionService,
+		viewService:     viewService,
 	}
 }
 
@@ -32,8 +39,10 @@ func (c *PostController) CreatePost(ctx *gin.Context) {
 	}
 
 	var input struct {
-		Title   string `json:"title" binding:"required,min=3,max=100"`
-		Content string `json:"content" binding:"required,min=10"`
+		Title      string `json:"title" binding:"required,min=3,max=100"`
+		Content    string `json:"content" binding:"required,min=10"`
+		Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted private password"`
+		Password   string `json:"password" binding:"max=128"`
 	}
 
 	// 绑定并验证输入
@@ -44,21 +53,29 @@ func (c *PostController) CreatePost(ctx *gin.Context) {
 	}
 
 	// 创建文章
-	post, err := c.postService.CreatePost(input.Title, input.Content, userID.(uint))
+	post, err := c.postService.CreatePost(input.Title, input.Content, userID.(uint), input.Visibility, input.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "创建文章失败: " + err.Error()})
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "密码保护的文章需要设置访问密码" || err.Error() == "无效的可见性设置" {
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": "创建文章失败: " + err.Error()})
 		return
 	}
 
 	// 返回结果
+	ctx.Header("ETag", versionETag(post.Version, nil))
 	ctx.JSON(http.StatusCreated, gin.H{
 		"message": "文章创建成功",
 		"post": gin.H{
-			"id":         post.ID,
-			"title":      post.Title,
-			"content":    post.Content,
-			"user_id":    post.UserID,
-			"created_at": post.CreatedAt,
+			"id":           post.ID,
+			"title":        post.Title,
+			"slug":         post.Slug,
+			"visibility":   post.Visibility,
+			"content":      post.Content,
+			"content_html": post.ContentHTML,
+			"user_id":      post.UserID,
+			"created_at":   post.CreatedAt,
 		},
 	})
 }
@@ -75,35 +92,198 @@ func (c *PostController) GetPost(ctx *gin.Context) {
 	}
 
 	// 获取文章信息
-	post, err := c.postService.GetPostByID(uint(id))
+	post, err := c.postService.GetPostByID(uint(id), viewerID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	c.respondPost(ctx, post)
+}
+
+// GetPostBySlug 根据 slug 获取单篇文章，历史 slug 永久重定向到当前 slug
+func (c *PostController) GetPostBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	// 获取文章信息
+	post, err := c.postService.GetPostBySlug(slug, viewerID(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
 		return
 	}
 
+	// 无权访问的私密文章按不存在处理，避免通过跳转暴露新 slug
+	if err := c.postService.CheckPostAccess(post, viewerID(ctx), ""); err != nil && err.Error() == "文章不存在" {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	// 旧 slug 跳转到当前 slug
+	if post.Slug != slug {
+		ctx.Redirect(http.StatusMovedPermanently, "/api/posts/by-slug/"+url.PathEscape(post.Slug))
+		return
+	}
+
+	c.respondPost(ctx, post)
+}
+
+// respondPost 检查访问权限后返回文章详情并记录阅读
+func (c *PostController) respondPost(ctx *gin.Context, post *model.Post) {
+	if err := c.postService.CheckPostAccess(post, viewerID(ctx), unlockToken(ctx)); err != nil {
+		if err.Error() == "文章需要密码" {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+				"code":  "PASSWORD_REQUIRED",
+				"id":    post.ID,
+				"title": post.Title,
+				"slug":  post.Slug,
+			})
+			return
+		}
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+
+	// 记录阅读
+	c.viewService.RecordView(post.ID, ctx.ClientIP(), ctx.Request.UserAgent(), ctx.Request.Referer())
+
+	// 获取互动统计
+	stats, err := c.reactionService.GetPostStats([]uint{post.ID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章失败: " + err.Error()})
+		return
+	}
+
+	body, err := json.Marshal(postDetail(post, stats[post.ID], c.postService.CommentState(post)))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章失败: " + err.Error()})
+		return
+	}
+
+	// ETag 包含版本号和内容摘要，评论和表态变化时同样会变化
+	etag := versionETag(post.Version, body)
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+	if inm := ctx.GetHeader("If-None-Match"); inm != "" && etagMatches(inm, etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	// 返回结果
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// UnlockPost 输入访问密码解锁密码保护的文章，返回访问令牌
+func (c *PostController) UnlockPost(ctx *gin.Context) {
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	token, err := c.postService.UnlockPost(uint(id), input.Password)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "文章不存在":
+			statusCode = http.StatusNotFound
+		case "文章未设置访问密码":
+			statusCode = http.StatusBadRequest
+		case "访问密码错误":
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"unlock_token": token})
+}
+
+// viewerID 获取当前访问者的用户ID，匿名访问时为0
+func viewerID(ctx *gin.Context) uint {
+	if userID, exists := ctx.Get("userID"); exists {
+		return userID.(uint)
+	}
+	return 0
+}
+
+// unlockToken 获取密码保护文章的访问令牌，可通过请求头 X-Post-Token 或查询参数 unlock_token 传入
+func unlockToken(ctx *gin.Context) string {
+	if token := ctx.GetHeader("X-Post-Token"); token != "" {
+		return token
+	}
+	return ctx.Query("unlock_token")
+}
+
+// checkPostAccess 检查当前访问者能否查看文章，无权访问时写入错误响应并返回 false
+func checkPostAccess(ctx *gin.Context, postService service.PostService, postID uint) bool {
+	err := postService.CheckPostAccessByID(postID, viewerID(ctx), unlockToken(ctx))
+	if err == nil {
+		return true
+	}
+	if err.Error() == "文章需要密码" {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "PASSWORD_REQUIRED"})
+	} else {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
+// postDetail 构造文章详情响应
+func postDetail(post *model.Post, stats *service.PostStats, commentState service.CommentState) gin.H {
 	// 处理评论数据
 	var comments []gin.H
 	for _, comment := range post.Comments {
 		comments = append(comments, gin.H{
-			"id":         comment.ID,
-			"content":    comment.Content,
-			"user_id":    comment.UserID,
-			"username":   comment.User.Username,
-			"created_at": comment.CreatedAt,
+			"id":           comment.ID,
+			"content":      comment.Content,
+			"content_html": comment.ContentHTML,
+			"parent_id":    comment.ParentID,
+			"user_id":      comment.UserID,
+			"username":     comment.User.Username,
+			"created_at":   comment.CreatedAt,
+			"edited":       comment.EditedAt != nil,
+			"edited_at":    comment.EditedAt,
 		})
 	}
 
-	// 返回结果
-	ctx.JSON(http.StatusOK, gin.H{
-		"id":         post.ID,
-		"title":      post.Title,
-		"content":    post.Content,
-		"user_id":    post.UserID,
-		"username":   post.User.Username,
-		"created_at": post.CreatedAt,
-		"updated_at": post.UpdatedAt,
-		"comments":   comments,
-	})
+	return gin.H{
+		"id":             post.ID,
+		"title":          post.Title,
+		"slug":           post.Slug,
+		"visibility":     post.Visibility,
+		"version":        post.Version,
+		"content":        post.Content,
+		"content_html":   post.ContentHTML,
+		"user_id":        post.UserID,
+		"username":       post.User.Username,
+		"created_at":     post.CreatedAt,
+		"updated_at":     post.UpdatedAt,
+		"reactions":      stats.Reactions,
+		"bookmark_count": stats.BookmarkCount,
+		"comment_state":  commentState,
+		"comments":       comments,
+	}
+}
+
+// postStats 批量获取列表中文章的互动统计
+func (c *PostController) postStats(posts []model.Post) (map[uint]*service.PostStats, error) {
+	postIDs := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	return c.reactionService.GetPostStats(postIDs)
 }
 
 // ListPosts 获取文章列表
@@ -119,8 +299,25 @@ func (c *PostController) ListPosts(ctx *gin.Context) {
 		pageSize = 10
 	}
 
-	// 获取文章列表
-	posts, total, err := c.postService.ListPosts(page, pageSize)
+	// 获取文章列表，带 cursor 参数时使用游标分页
+	var posts []model.Post
+	var pagination gin.H
+	if cursor, ok, withTotal := cursorQuery(ctx); ok {
+		var cursorPage *service.CursorPage
+		posts, cursorPage, err = c.postService.ListPostsByCursor(viewerID(ctx), cursor, pageSize, withTotal)
+		if err == nil {
+			pagination = cursorPagination(cursorPage, pageSize)
+		}
+	} else {
+		var total int64
+		posts, total, err = c.postService.ListPosts(viewerID(ctx), page, pageSize)
+		pagination = offsetPagination(total, page, pageSize)
+	}
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取文章列表失败: " + err.Error()})
+		return
+	}
+	stats, err := c.postStats(posts)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取文章列表失败: " + err.Error()})
 		return
@@ -130,24 +327,23 @@ func (c *PostController) ListPosts(ctx *gin.Context) {
 	var postList []gin.H
 	for _, post := range posts {
 		postList = append(postList, gin.H{
-			"id":         post.ID,
-			"title":      post.Title,
-			"user_id":    post.UserID,
-			"username":   post.User.Username,
-			"created_at": post.CreatedAt,
-			"updated_at": post.UpdatedAt,
+			"id":             post.ID,
+			"title":          post.Title,
+			"slug":           post.Slug,
+			"visibility":     post.Visibility,
+			"user_id":        post.UserID,
+			"username":       post.User.Username,
+			"created_at":     post.CreatedAt,
+			"updated_at":     post.UpdatedAt,
+			"reactions":      stats[post.ID].Reactions,
+			"bookmark_count": stats[post.ID].BookmarkCount,
 		})
 	}
 
 	// 返回结果
 	ctx.JSON(http.StatusOK, gin.H{
 		"posts":      postList,
-		"pagination": gin.H{
-			"total":     total,
-			"page":      page,
-			"page_size": pageSize,
-			"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
-		},
+		"pagination": pagination,
 	})
 }
 
@@ -171,8 +367,10 @@ func (c *PostController) UpdatePost(ctx *gin.Context) {
 	}
 
 	var input struct {
-		Title   string `json:"title" binding:"required,min=3,max=100"`
-		Content string `json:"content" binding:"required,min=10"`
+		Title      string `json:"title" binding:"required,min=3,max=100"`
+		Content    string `json:"content" binding:"required,min=10"`
+		Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted private password"`
+		Password   string `json:"password" binding:"max=128"`
 	}
 
 	// 绑定并验证输入
@@ -182,31 +380,176 @@ func (c *PostController) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
+	// 更新前必须通过 If-Match 提供当前版本，防止并发编辑互相覆盖
+	version, ok := requireIfMatch(ctx)
+	if !ok {
+		return
+	}
+
 	// 更新文章
-	post, err := c.postService.UpdatePost(uint(id), input.Title, input.Content, userID.(uint))
+	post, err := c.postService.UpdatePost(uint(id), input.Title, input.Content, userID.(uint), input.Visibility, input.Password, version)
+	writePostUpdate(ctx, post, err)
+}
+
+// PatchPost 部分更新文章，请求体为 JSON Merge Patch（RFC 7396）
+func (c *PostController) PatchPost(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("更新文章时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	// 解析并逐字段验证
+	patch, ok := bindMergePatch(ctx, "title", "content", "visibility", "password")
+	if !ok {
+		return
+	}
+	postPatch := service.PostPatch{
+		Title:      patch.stringField("title", 3, 100, false),
+		Content:    patch.stringField("content", 10, 0, false),
+		Visibility: patch.oneOfField("visibility", model.VisibilityPublic, model.VisibilityUnlisted, model.VisibilityPrivate, model.VisibilityPassword),
+		Password:   patch.stringField("password", 1, 128, true),
+	}
+	if !patch.valid(ctx) {
+		return
+	}
+	// visibility 为 null 时恢复为公开
+	if postPatch.Visibility != nil && *postPatch.Visibility == "" {
+		public := model.VisibilityPublic
+		postPatch.Visibility = &public
+	}
+
+	version, ok := requireIfMatch(ctx)
+	if !ok {
+		return
+	}
+
+	// 更新文章
+	post, err := c.postService.PatchPost(uint(id), userID.(uint), postPatch, version)
+	writePostUpdate(ctx, post, err)
+}
+
+// requireIfMatch 解析 If-Match 中的版本号，缺失或无效时写入错误响应并返回 false
+func requireIfMatch(ctx *gin.Context) (uint, bool) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": "缺少 If-Match 请求头"})
+		return 0, false
+	}
+	version, ok := ifMatchVersion(ifMatch)
+	if !ok {
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": "无效的 If-Match 请求头"})
+		return 0, false
+	}
+	return version, true
+}
+
+// writePostUpdate 写入文章更新结果
+func writePostUpdate(ctx *gin.Context, post *model.Post, err error) {
+	if err != nil {
+		if err.Error() == "文章已被修改" {
+			ctx.Header("ETag", versionETag(post.Version, nil))
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{
+				"error":           "文章已被他人修改，请获取最新版本后重试",
+				"current_version": post.Version,
+			})
+			return
+		}
 		statusCode := http.StatusInternalServerError
 		if err.Error() == "文章不存在" {
 			statusCode = http.StatusNotFound
 		} else if err.Error() == "没有权限更新此文章" {
 			statusCode = http.StatusForbidden
+		} else if err.Error() == "密码保护的文章需要设置访问密码" || err.Error() == "无效的可见性设置" {
+			statusCode = http.StatusBadRequest
 		}
 		ctx.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
 
 	// 返回结果
+	ctx.Header("ETag", versionETag(post.Version, nil))
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "文章更新成功",
 		"post": gin.H{
-			"id":         post.ID,
-			"title":      post.Title,
-			"content":    post.Content,
-			"updated_at": post.UpdatedAt,
+			"id":           post.ID,
+			"title":        post.Title,
+			"slug":         post.Slug,
+			"visibility":   post.Visibility,
+			"content":      post.Content,
+			"content_html": post.ContentHTML,
+			"version":      post.Version,
+			"updated_at":   post.UpdatedAt,
 		},
 	})
 }
 
+// UpdateCommentSettings 更新文章的评论设置
+func (c *PostController) UpdateCommentSettings(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("更新评论设置时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	var input struct {
+		Mode          string `json:"mode" binding:"required,oneof=open disabled locked"`
+		AutoCloseDays *int   `json:"auto_close_days" binding:"omitempty,min=0"` // 为空时使用站点默认值，0 表示不自动关闭
+	}
+
+	// 绑定并验证输入
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		logrus.Warnf("更新评论设置输入验证失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	// 更新评论设置
+	post, err := c.postService.UpdateCommentSettings(uint(id), userID.(uint), input.Mode, input.AutoCloseDays)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "文章不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "没有权限更新此文章" {
+			statusCode = http.StatusForbidden
+		} else if err.Error() == "无效的评论模式" {
+			statusCode = http.StatusBadRequest
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":         "评论设置已更新",
+		"mode":            post.CommentMode,
+		"auto_close_days": post.CommentAutoCloseDays,
+		"comment_state":   c.postService.CommentState(post),
+	})
+}
+
 // DeletePost 删除文章
 func (c *PostController) DeletePost(ctx *gin.Context) {
 	// 获取当前用户ID
@@ -265,8 +608,25 @@ func (c *PostController) GetUserPosts(ctx *gin.Context) {
 		pageSize = 10
 	}
 
-	// 获取用户的文章列表
-	posts, total, err := c.postService.GetUserPosts(uint(userID), page, pageSize)
+	// 获取用户的文章列表，带 cursor 参数时使用游标分页
+	var posts []model.Post
+	var pagination gin.H
+	if cursor, ok, withTotal := cursorQuery(ctx); ok {
+		var cursorPage *service.CursorPage
+		posts, cursorPage, err = c.postService.GetUserPostsByCursor(uint(userID), viewerID(ctx), cursor, pageSize, withTotal)
+		if err == nil {
+			pagination = cursorPagination(cursorPage, pageSize)
+		}
+	} else {
+		var total int64
+		posts, total, err = c.postService.GetUserPosts(uint(userID), viewerID(ctx), page, pageSize)
+		pagination = offsetPagination(total, page, pageSize)
+	}
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取用户文章列表失败: " + err.Error()})
+		return
+	}
+	stats, err := c.postStats(posts)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取用户文章列表失败: " + err.Error()})
 		return
@@ -276,21 +636,20 @@ func (c *PostController) GetUserPosts(ctx *gin.Context) {
 	var postList []gin.H
 	for _, post := range posts {
 		postList = append(postList, gin.H{
-			"id":         post.ID,
-			"title":      post.Title,
-			"created_at": post.CreatedAt,
-			"updated_at": post.UpdatedAt,
+			"id":             post.ID,
+			"title":          post.Title,
+			"slug":           post.Slug,
+			"visibility":     post.Visibility,
+			"created_at":     post.CreatedAt,
+			"updated_at":     post.UpdatedAt,
+			"reactions":      stats[post.ID].Reactions,
+			"bookmark_count": stats[post.ID].BookmarkCount,
 		})
 	}
 
 	// 返回结果
 	ctx.JSON(http.StatusOK, gin.H{
 		"posts":      postList,
-		"pagination": gin.H{
-			"total":     total,
-			"page":      page,
-			"page_size": pageSize,
-			"pages":     (total + int64(pageSize) - 1) / int64(pageSize),
-		},
+		"pagination": pagination,
 	})
 }
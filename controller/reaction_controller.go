@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// ReactionController 文章表态与收藏控制器
+type ReactionController struct {
+	reactionService service.ReactionService
+	postService     service.PostService
+}
+
+// NewReactionController 创建文章表态与收藏控制器实例
+func NewReactionController(reactionService service.ReactionService, postService service.PostService) *ReactionController {
+	return &ReactionController{
+		reactionService: reactionService,
+		postService:     postService,
+	}
+}
+
+// ListReactionTypes 获取支持的表态类型
+func (c *ReactionController) ListReactionTypes(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"types": c.reactionService.ReactionTypes()})
+}
+
+// AddReaction 对文章添加表态
+func (c *ReactionController) AddReaction(ctx *gin.Context) {
+	c.handleReaction(ctx, true)
+}
+
+// RemoveReaction 取消对文章的表态
+func (c *ReactionController) RemoveReaction(ctx *gin.Context) {
+	c.handleReaction(ctx, false)
+}
+
+// handleReaction 处理添加或取消表态，成功后返回文章最新的表态统计
+func (c *ReactionController) handleReaction(ctx *gin.Context, add bool) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("文章表态时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	reactionType := ctx.Param("type")
+	if add {
+		// 添加表态前检查文章访问权限，取消表态不受限制
+		if !checkPostAccess(ctx, c.postService, uint(id)) {
+			return
+		}
+		err = c.reactionService.AddReaction(uint(id), userID.(uint), reactionType)
+	} else {
+		err = c.reactionService.RemoveReaction(uint(id), userID.(uint), reactionType)
+	}
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "不支持的表态类型":
+			statusCode = http.StatusBadRequest
+		case "文章不存在":
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回最新的统计
+	stats, err := c.reactionService.GetPostStats([]uint{uint(id)})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取表态统计失败: " + err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"reactions": stats[uint(id)].Reactions})
+}
+
+// AddBookmark 收藏文章
+func (c *ReactionController) AddBookmark(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("收藏文章时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	// 检查文章访问权限
+	if !checkPostAccess(ctx, c.postService, uint(id)) {
+		return
+	}
+
+	if err := c.reactionService.AddBookmark(uint(id), userID.(uint)); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "文章不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "收藏成功"})
+}
+
+// RemoveBookmark 取消收藏文章
+func (c *ReactionController) RemoveBookmark(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("取消收藏时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	if err := c.reactionService.RemoveBookmark(uint(id), userID.(uint)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "取消收藏失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "已取消收藏"})
+}
+
+// ListBookmarks 获取当前用户的收藏列表（游标分页）
+func (c *ReactionController) ListBookmarks(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取收藏列表时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	cursor, _, withTotal := cursorQuery(ctx)
+
+	// 获取收藏列表
+	bookmarks, page, err := c.reactionService.ListBookmarks(userID.(uint), cursor, pageSize, withTotal)
+	if err != nil {
+		ctx.JSON(listErrorStatus(err), gin.H{"error": "获取收藏列表失败: " + err.Error()})
+		return
+	}
+
+	// 获取文章互动统计
+	postIDs := make([]uint, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		postIDs = append(postIDs, bookmark.PostID)
+	}
+	stats, err := c.reactionService.GetPostStats(postIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取收藏列表失败: " + err.Error()})
+		return
+	}
+
+	// 处理收藏数据
+	var bookmarkList []gin.H
+	for _, bookmark := range bookmarks {
+		bookmarkList = append(bookmarkList, gin.H{
+			"id":            bookmark.ID,
+			"bookmarked_at": bookmark.CreatedAt,
+			"post": gin.H{
+				"id":             bookmark.Post.ID,
+				"title":          bookmark.Post.Title,
+				"slug":           bookmark.Post.Slug,
+				"user_id":        bookmark.Post.UserID,
+				"username":       bookmark.Post.User.Username,
+				"created_at":     bookmark.Post.CreatedAt,
+				"reactions":      stats[bookmark.PostID].Reactions,
+				"bookmark_count": stats[bookmark.PostID].BookmarkCount,
+			},
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"bookmarks":  bookmarkList,
+		"pagination": cursorPagination(page, pageSize),
+	})
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionController 会话控制器
+type SessionController struct {
+	sessionService service.SessionService
+}
+
+// NewSessionController 创建会话控制器实例
+func NewSessionController(sessionService service.SessionService) *SessionController {
+	return &SessionController{
+		sessionService: sessionService,
+	}
+}
+
+// ListSessions 获取当前用户的登录会话列表
+func (c *SessionController) ListSessions(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("获取会话列表时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+	currentSessionID := ctx.GetString("sessionID")
+
+	// 获取会话列表
+	sessions, err := c.sessionService.ListSessions(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取会话列表失败: " + err.Error()})
+		return
+	}
+
+	// 处理会话数据
+	var sessionList []gin.H
+	for _, session := range sessions {
+		sessionList = append(sessionList, gin.H{
+			"id":           session.ID,
+			"user_agent":   session.UserAgent,
+			"ip":           session.IP,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"current":      session.SessionID == currentSessionID,
+		})
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"sessions": sessionList})
+}
+
+// RevokeSession 注销指定会话
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("注销会话时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 获取会话ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的会话ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的会话ID"})
+		return
+	}
+
+	// 注销会话
+	err = c.sessionService.RevokeSession(uint(id), userID.(uint))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "会话不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": "会话已注销"})
+}
+
+// RevokeOtherSessions 注销除当前会话外的所有会话
+func (c *SessionController) RevokeOtherSessions(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("注销其他会话时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	// 注销其他会话
+	count, err := c.sessionService.RevokeOtherSessions(userID.(uint), ctx.GetString("sessionID"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "注销其他会话失败: " + err.Error()})
+		return
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "其他会话已注销",
+		"revoked": count,
+	})
+}
+
+// CreateStreamTicket 签发实时推送的一次性票据，用于 EventSource 连接的 ticket 查询参数
+func (c *SessionController) CreateStreamTicket(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("签发推送票据时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	ticket, err := c.sessionService.CreateStreamTicket(userID.(uint), ctx.GetString("sessionID"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "签发推送票据失败: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"ticket":     ticket.Ticket,
+		"expires_at": ticket.ExpiresAt,
+	})
+}
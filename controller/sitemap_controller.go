@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SitemapController 站点地图控制器
+type SitemapController struct {
+	sitemapService service.SitemapService
+}
+
+// NewSitemapController 创建站点地图控制器实例
+func NewSitemapController(sitemapService service.SitemapService) *SitemapController {
+	return &SitemapController{
+		sitemapService: sitemapService,
+	}
+}
+
+// Sitemap 站点地图入口 /sitemap.xml
+func (c *SitemapController) Sitemap(ctx *gin.Context) {
+	c.render(ctx, 0)
+}
+
+// SitemapPage 分页站点地图 /sitemap/:page（如 /sitemap/2.xml）
+func (c *SitemapController) SitemapPage(ctx *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(ctx.Param("page"), ".xml"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "站点地图不存在"})
+		return
+	}
+	c.render(ctx, page)
+}
+
+// RobotsTxt 输出 robots.txt
+func (c *SitemapController) RobotsTxt(ctx *gin.Context) {
+	writeConditional(ctx, "text/plain; charset=utf-8", []byte(c.sitemapService.RobotsTxt()), time.Time{})
+}
+
+// render 输出站点地图
+func (c *SitemapController) render(ctx *gin.Context, page int) {
+	body, lastModified, err := c.sitemapService.Sitemap(page)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "站点地图不存在" {
+			statusCode = http.StatusNotFound
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	writeConditional(ctx, "application/xml; charset=utf-8", body, lastModified)
+}
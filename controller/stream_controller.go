@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"blog-backend/pubsub"
+	"blog-backend/service"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// StreamController 实时推送控制器，使用 Server-Sent Events
+type StreamController struct {
+	hub          *pubsub.Hub
+	postService  service.PostService
+	blockService service.BlockService
+	heartbeat    time.Duration
+}
+
+// NewStreamController 创建实时推送控制器实例
+func NewStreamController(hub *pubsub.Hub, postService service.PostService, blockService service.BlockService, heartbeat string) *StreamController {
+	interval, err := time.ParseDuration(heartbeat)
+	if err != nil || interval <= 0 {
+		logrus.Warnf("无效的心跳间隔 %q，使用默认值25s", heartbeat)
+		interval = 25 * time.Second
+	}
+	return &StreamController{
+		hub:          hub,
+		postService:  postService,
+		blockService: blockService,
+		heartbeat:    interval,
+	}
+}
+
+// PostStream 推送文章的评论新增、更新和删除事件，不推送访问者在订阅时已屏蔽或隐藏的用户的评论
+func (c *StreamController) PostStream(ctx *gin.Context) {
+	// 获取文章ID
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的文章ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+
+	// 检查文章访问权限
+	if !checkPostAccess(ctx, c.postService, uint(id)) {
+		return
+	}
+
+	// 加载访问者屏蔽和隐藏的用户
+	var hidden map[uint]bool
+	if viewer := viewerID(ctx); viewer != 0 {
+		if hidden, err = c.blockService.HiddenUserIDs(viewer); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.stream(ctx, service.PostTopic(uint(id)), func(event pubsub.Event) bool {
+		if len(hidden) == 0 || (event.Type != service.EventCommentCreated && event.Type != service.EventCommentUpdated) {
+			return false
+		}
+		var comment service.CommentEvent
+		if err := json.Unmarshal(event.Data, &comment); err != nil {
+			return false
+		}
+		return hidden[comment.UserID]
+	})
+}
+
+// NotificationStream 推送当前用户的新通知
+func (c *StreamController) NotificationStream(ctx *gin.Context) {
+	c.stream(ctx, service.UserTopic(ctx.GetUint("userID")), nil)
+}
+
+// stream 订阅主题并以 SSE 格式持续输出事件，skip 返回 true 的事件不输出，定期发送心跳注释保持连接。
+// 连接因处理过慢被断开时先发送 lagged 事件，客户端应重新拉取数据后再重连
+func (c *StreamController) stream(ctx *gin.Context, topic string, skip func(event pubsub.Event) bool) {
+	sub, err := c.hub.Subscribe(topic)
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	defer sub.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	fmt.Fprintf(ctx.Writer, "retry: 3000\n\n")
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(c.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprintf(ctx.Writer, ": ping\n\n")
+			ctx.Writer.Flush()
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Lagged() {
+					fmt.Fprintf(ctx.Writer, "event: lagged\ndata: {}\n\n")
+					ctx.Writer.Flush()
+				}
+				return
+			}
+			if skip != nil && skip(event) {
+				continue
+			}
+			fmt.Fprintf(ctx.Writer, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+			ctx.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"blog-backend/model"
+	"blog-backend/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// TrashController 回收站控制器，挂在管理员路由下时可操作所有用户的内容
+type TrashController struct {
+	trashService service.TrashService
+}
+
+// NewTrashController 创建回收站控制器实例
+func NewTrashController(trashService service.TrashService) *TrashController {
+	return &TrashController{
+		trashService: trashService,
+	}
+}
+
+// ListPosts 获取回收站中的文章
+func (c *TrashController) ListPosts(ctx *gin.Context) {
+	userID, asAdmin, ok := trashUser(ctx)
+	if !ok {
+		return
+	}
+	page, pageSize := trashPage(ctx)
+
+	posts, total, err := c.trashService.ListPosts(userID, asAdmin, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取回收站文章失败: " + err.Error()})
+		return
+	}
+
+	// 处理文章数据
+	var postList []gin.H
+	for _, post := range posts {
+		postList = append(postList, gin.H{
+			"id":         post.ID,
+			"title":      post.Title,
+			"slug":       post.Slug,
+			"user_id":    post.UserID,
+			"username":   post.User.Username,
+			"created_at": post.CreatedAt,
+			"deleted_at": post.DeletedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"posts":      postList,
+		"pagination": offsetPagination(total, page, pageSize),
+	})
+}
+
+// ListComments 获取回收站中的评论
+func (c *TrashController) ListComments(ctx *gin.Context) {
+	userID, asAdmin, ok := trashUser(ctx)
+	if !ok {
+		return
+	}
+	page, pageSize := trashPage(ctx)
+
+	comments, total, err := c.trashService.ListComments(userID, asAdmin, page, pageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "获取回收站评论失败: " + err.Error()})
+		return
+	}
+
+	// 处理评论数据
+	var commentList []gin.H
+	for _, comment := range comments {
+		commentList = append(commentList, gin.H{
+			"id":         comment.ID,
+			"content":    comment.Content,
+			"post_id":    comment.PostID,
+			"user_id":    comment.UserID,
+			"username":   comment.User.Username,
+			"created_at": comment.CreatedAt,
+			"deleted_at": comment.DeletedAt.Time,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"comments":   commentList,
+		"pagination": offsetPagination(total, page, pageSize),
+	})
+}
+
+// RestorePost 恢复文章
+func (c *TrashController) RestorePost(ctx *gin.Context) {
+	c.handle(ctx, c.trashService.RestorePost, "文章恢复成功")
+}
+
+// PurgePost 永久删除文章
+func (c *TrashController) PurgePost(ctx *gin.Context) {
+	c.handle(ctx, c.trashService.PurgePost, "文章已永久删除")
+}
+
+// RestoreComment 恢复评论
+func (c *TrashController) RestoreComment(ctx *gin.Context) {
+	c.handle(ctx, c.trashService.RestoreComment, "评论恢复成功")
+}
+
+// PurgeComment 永久删除评论
+func (c *TrashController) PurgeComment(ctx *gin.Context) {
+	c.handle(ctx, c.trashService.PurgeComment, "评论已永久删除")
+}
+
+// handle 处理回收站中单条内容的恢复或永久删除
+func (c *TrashController) handle(ctx *gin.Context, action func(id, userID uint, asAdmin bool) error, message string) {
+	userID, asAdmin, ok := trashUser(ctx)
+	if !ok {
+		return
+	}
+
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		logrus.Warnf("无效的ID: %s", idStr)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	if err := action(uint(id), userID, asAdmin); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch err.Error() {
+		case "文章不在回收站中", "评论不在回收站中":
+			statusCode = http.StatusNotFound
+		case "没有权限操作此文章", "没有权限操作此评论":
+			statusCode = http.StatusForbidden
+		case "所属文章已删除，请先恢复文章":
+			statusCode = http.StatusConflict
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// trashUser 获取当前用户ID，以及是否通过管理员路由访问
+func trashUser(ctx *gin.Context) (uint, bool, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("访问回收站时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return 0, false, false
+	}
+	return userID.(uint), ctx.GetString("userRole") == model.RoleAdmin, true
+}
+
+// trashPage 解析回收站列表的分页参数
+func trashPage(ctx *gin.Context) (int, int) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"blog-backend/config"
+	"blog-backend/model"
 	"blog-backend/service"
 	"blog-backend/utils"
 	"net/http"
@@ -13,24 +14,27 @@ import (
 
 // UserController 用户控制器
 type UserController struct {
-	userService service.UserService
-	cfg         *config.Config
+	userService    service.UserService
+	sessionService service.SessionService
+	cfg            *config.Config
 }
 
 // NewUserController 创建用户控制器实例
-func NewUserController(userService service.UserService, cfg *config.Config) *UserController {
+func NewUserController(userService service.UserService, sessionService service.SessionService, cfg *config.Config) *UserController {
 	return &UserController{
-		userService: userService,
-		cfg:         cfg,
+		userService:    userService,
+		sessionService: sessionService,
+		cfg:            cfg,
 	}
 }
 
 // Register 用户注册
 func (c *UserController) Register(ctx *gin.Context) {
 	var input struct {
-		Username string `json:"username" binding:"required,min=3,max=50"`
-		Email    string `json:"email" binding:"required,email"`
-		Password string `json:"password" binding:"required,min=6"`
+		Username   string `json:"username" binding:"required,min=3,max=50"`
+		Email      string `json:"email" binding:"required,email"`
+		Password   string `json:"password" binding:"required"` // 密码强度由密码策略校验
+		InviteCode string `json:"invite_code"`
 	}
 
 	// 绑定并验证输入
@@ -41,19 +45,29 @@ func (c *UserController) Register(ctx *gin.Context) {
 	}
 
 	// 创建用户
-	user, err := c.userService.CreateUser(input.Username, input.Email, input.Password)
+	user, err := c.userService.CreateUser(input.Username, input.Email, input.Password, input.InviteCode, ctx.Request.UserAgent(), ctx.ClientIP())
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		statusCode := http.StatusBadRequest
+		if err.Error() == "当前仅限邀请注册" || err.Error() == "邀请码无效或已失效" || err.Error() == "注册信息被识别为垃圾信息" {
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
 
+	message := "用户注册成功"
+	if user.Status == model.UserStatusPending {
+		message = "用户注册成功，请等待审核"
+	}
+
 	// 返回结果
 	ctx.JSON(http.StatusCreated, gin.H{
-		"message": "用户注册成功",
+		"message": message,
 		"user": gin.H{
-			"id":       user.ID,
-			"username": user.Username,
-			"email":    user.Email,
+			"id":         user.ID,
+			"username":   user.Username,
+			"email":      user.Email,
+			"status":     user.Status,
 			"created_at": user.CreatedAt,
 		},
 	})
@@ -76,14 +90,17 @@ func (c *UserController) Login(ctx *gin.Context) {
 	// 验证用户
 	user, err := c.userService.Login(input.Username, input.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		statusCode := http.StatusUnauthorized
+		if err.Error() == "账号正在等待审核" || err.Error() == "账号审核未通过" {
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, c.cfg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+	// 创建会话并生成JWT令牌
+	token, ok := issueLoginToken(ctx, c.sessionService, c.cfg, user.ID)
+	if !ok {
 		return
 	}
 
@@ -99,6 +116,49 @@ func (c *UserController) Login(ctx *gin.Context) {
 	})
 }
 
+// ChangePassword 修改当前用户密码，成功后注销其他会话
+func (c *UserController) ChangePassword(ctx *gin.Context) {
+	// 获取当前用户ID
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		logrus.Warn("修改密码时未获取到用户ID")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return
+	}
+
+	var input struct {
+		OldPassword string `json:"old_password" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+
+	// 绑定并验证输入
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		logrus.Warnf("修改密码输入验证失败: %v", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的输入数据: " + err.Error()})
+		return
+	}
+
+	// 修改密码
+	if err := c.userService.ChangePassword(userID.(uint), input.OldPassword, input.NewPassword); err != nil {
+		statusCode := http.StatusBadRequest
+		if err.Error() == "用户不存在" {
+			statusCode = http.StatusNotFound
+		} else if err.Error() == "原密码错误" {
+			statusCode = http.StatusForbidden
+		}
+		ctx.JSON(statusCode, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 注销其他会话
+	if _, err := c.sessionService.RevokeOtherSessions(userID.(uint), ctx.GetString("sessionID")); err != nil {
+		logrus.Errorf("用户 %d 修改密码后注销其他会话失败: %v", userID, err)
+	}
+
+	// 返回结果
+	ctx.JSON(http.StatusOK, gin.H{"message": "密码修改成功"})
+}
+
 // GetUser 获取用户信息
 func (c *UserController) GetUser(ctx *gin.Context) {
 	// 获取用户ID
@@ -125,3 +185,22 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 		"created_at": user.CreatedAt,
 	})
 }
+
+// issueLoginToken 为登录成功的用户创建会话并生成JWT令牌，失败时直接写入错误响应
+func issueLoginToken(ctx *gin.Context, sessionService service.SessionService, cfg *config.Config, userID uint) (string, bool) {
+	// 创建登录会话
+	session, err := sessionService.CreateSession(userID, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "创建会话失败"})
+		return "", false
+	}
+
+	// 生成JWT令牌
+	token, err := utils.GenerateToken(userID, session.SessionID, cfg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+		return "", false
+	}
+
+	return token, true
+}
@@ -4,10 +4,20 @@ import (
 	"blog-backend/config"
 	"blog-backend/controller"
 	"blog-backend/model"
+	"blog-backend/pubsub"
 	"blog-backend/router"
 	"blog-backend/service"
+	"blog-backend/storage"
 	"blog-backend/utils"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -32,20 +42,108 @@ func main() {
 	// 自动迁移数据表
 	model.AutoMigrate(db)
 
+	// 初始化文件存储
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		logrus.Fatalf("文件存储初始化失败: %v", err)
+	}
+
+	// 初始化实时推送
+	broker, err := pubsub.NewBroker(cfg)
+	if err != nil {
+		logrus.Fatalf("消息代理初始化失败: %v", err)
+	}
+	hub, err := pubsub.NewHub(broker, cfg.StreamBufferSize)
+	if err != nil {
+		logrus.Fatalf("实时推送初始化失败: %v", err)
+	}
+
 	// 初始化服务
-	userService := service.NewUserService(db)
-	postService := service.NewPostService(db)
-	commentService := service.NewCommentService(db)
+	spamService := service.NewSpamService(db, cfg)
+	notificationService := service.NewNotificationService(db, hub)
+	userService := service.NewUserService(db, cfg, spamService)
+	inviteService := service.NewInviteService(db)
+	postService := service.NewPostService(db, cfg, notificationService)
+	commentService := service.NewCommentService(db, cfg, spamService, notificationService, hub)
+	sessionService := service.NewSessionService(db, cfg)
+	oauthService := service.NewOAuthService(db, cfg)
+	mediaService := service.NewMediaService(db, cfg, store)
+	feedService := service.NewFeedService(db, cfg)
+	sitemapService := service.NewSitemapService(db, cfg)
+	reactionService := service.NewReactionService(db, cfg)
+	followService := service.NewFollowService(db)
+	blockService := service.NewBlockService(db)
+	viewService := service.NewViewService(db, cfg)
+	trashService := service.NewTrashService(db, cfg)
+	moderationService := service.NewModerationService(db, cfg, spamService, notificationService, hub)
+
+	// 为历史文章生成slug
+	if err := postService.BackfillSlugs(); err != nil {
+		logrus.Errorf("生成文章slug失败: %v", err)
+	}
+
+	// 为历史文章和评论渲染HTML
+	if err := postService.BackfillContentHTML(); err != nil {
+		logrus.Errorf("渲染文章HTML失败: %v", err)
+	}
+	if err := commentService.BackfillContentHTML(); err != nil {
+		logrus.Errorf("渲染评论HTML失败: %v", err)
+	}
+
+	// 初始化管理员账号
+	if cfg.AdminUsernames != "" {
+		userService.PromoteAdmins(strings.Split(cfg.AdminUsernames, ","))
+	}
 
 	// 初始化控制器
-	userController := controller.NewUserController(userService, cfg)
-	postController := controller.NewPostController(postService)
-	commentController := controller.NewCommentController(commentService)
+	userController := controller.NewUserController(userService, sessionService, cfg)
+	postController := controller.NewPostController(postService, reactionService, viewService)
+	commentController := controller.NewCommentController(commentService, postService)
+	sessionController := controller.NewSessionController(sessionService)
+	oauthController := controller.NewOAuthController(oauthService, sessionService, cfg)
+	adminController := controller.NewAdminController(userService, inviteService)
+	mediaController := controller.NewMediaController(mediaService, cfg)
+	feedController := controller.NewFeedController(feedService)
+	sitemapController := controller.NewSitemapController(sitemapService)
+	reactionController := controller.NewReactionController(reactionService, postService)
+	analyticsController := controller.NewAnalyticsController(viewService)
+	trashController := controller.NewTrashController(trashService)
+	moderationController := controller.NewModerationController(moderationService)
+	notificationController := controller.NewNotificationController(notificationService)
+	streamController := controller.NewStreamController(hub, postService, blockService, cfg.StreamHeartbeat)
+	followController := controller.NewFollowController(followService, reactionService)
+	blockController := controller.NewBlockController(blockService)
 
 	// 设置路由
-	r := router.SetupRouter(userController, postController, commentController, cfg)
+	r := router.SetupRouter(userController, postController, commentController, sessionController, oauthController, adminController, mediaController, feedController, sitemapController, reactionController, analyticsController, trashController, moderationController, notificationController, streamController, followController, blockController, userService, sessionService, cfg)
 
 	// 启动服务器
-	logrus.Printf("服务器启动在端口 %s", cfg.ServerPort)
-	logrus.Fatal(r.Run(fmt.Sprintf(":%s", cfg.ServerPort)))
+	viewService.Start()
+	trashService.Start()
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", cfg.ServerPort),
+		Handler: r,
+	}
+	// 关闭时结束所有推送长连接，否则 Shutdown 会一直等待
+	srv.RegisterOnShutdown(hub.Close)
+	go func() {
+		logrus.Printf("服务器启动在端口 %s", cfg.ServerPort)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("服务器启动失败: %v", err)
+		}
+	}()
+
+	// 等待退出信号，关闭服务器后写入缓冲中的阅读数
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	logrus.Info("正在关闭服务器...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		logrus.Errorf("服务器关闭失败: %v", err)
+	}
+	viewService.Stop()
+	trashService.Stop()
 }
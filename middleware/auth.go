@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"blog-backend/config"
+	"blog-backend/service"
 	"blog-backend/utils"
 	"net/http"
 	"strings"
@@ -12,7 +13,7 @@ import (
 )
 
 // AuthMiddleware JWT认证中间件
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+func AuthMiddleware(cfg *config.Config, sessionService service.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 从请求头中获取Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -47,8 +48,63 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// 将用户ID存入上下文
+		// 检查会话是否已被注销
+		if err := sessionService.ValidateSession(claims.SessionID, claims.UserID); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		// 将用户ID和会话ID存入上下文
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware 可选认证中间件，携带有效令牌时将用户信息存入上下文，
+// 未携带或令牌无效时按匿名访问处理
+func OptionalAuthMiddleware(cfg *config.Config, sessionService service.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			claims := &utils.Claims{}
+			token, err := jwt.ParseWithClaims(parts[1], claims, func(token *jwt.Token) (interface{}, error) {
+				return []byte(cfg.JWTSecret), nil
+			})
+			if err == nil && token.Valid && sessionService.ValidateSession(claims.SessionID, claims.UserID) == nil {
+				c.Set("userID", claims.UserID)
+				c.Set("sessionID", claims.SessionID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// StreamAuthMiddleware 实时推送认证中间件。EventSource 无法设置请求头，可以先签发一次性票据，
+// 再通过 ticket 查询参数认证；未携带票据时按 Authorization 请求头认证，required 为 false 时允许匿名访问
+func StreamAuthMiddleware(cfg *config.Config, sessionService service.SessionService, required bool) gin.HandlerFunc {
+	headerAuth := OptionalAuthMiddleware(cfg, sessionService)
+	if required {
+		headerAuth = AuthMiddleware(cfg, sessionService)
+	}
+	return func(c *gin.Context) {
+		value := c.Query("ticket")
+		if value == "" {
+			headerAuth(c)
+			return
+		}
+
+		ticket, err := sessionService.RedeemStreamTicket(value)
+		if err != nil {
+			logrus.Warnf("推送票据无效: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("userID", ticket.UserID)
+		c.Set("sessionID", ticket.SessionID)
 		c.Next()
 	}
 }
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams 访问日志中需要隐藏值的查询参数
+var redactedParams = []string{"access_token", "ticket", "unlock_token"}
+
+// Logger 访问日志中间件，格式与 gin 默认日志一致，并隐藏查询参数中的令牌
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Formatter: func(param gin.LogFormatterParams) string {
+			var statusColor, methodColor, resetColor string
+			if param.IsOutputColor() {
+				statusColor = param.StatusCodeColor()
+				methodColor = param.MethodColor()
+				resetColor = param.ResetColor()
+			}
+
+			if param.Latency > time.Minute {
+				param.Latency = param.Latency.Truncate(time.Second)
+			}
+			return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+				param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+				statusColor, param.StatusCode, resetColor,
+				param.Latency,
+				param.ClientIP,
+				methodColor, param.Method, resetColor,
+				redactPath(param.Path),
+				param.ErrorMessage,
+			)
+		},
+	})
+}
+
+// redactPath 将路径中敏感查询参数的值替换为 REDACTED
+func redactPath(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// 无法解析时不输出查询参数
+		return base + "?REDACTED"
+	}
+
+	redacted := false
+	for _, key := range redactedParams {
+		if values, ok := query[key]; ok {
+			for i := range values {
+				values[i] = "REDACTED"
+			}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return base + "?" + query.Encode()
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"blog-backend/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// RequireRole 角色校验中间件，需在AuthMiddleware之后使用
+func RequireRole(userService service.UserService, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+			c.Abort()
+			return
+		}
+
+		user, err := userService.GetUserByID(userID.(uint))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				// 将用户角色存入上下文
+				c.Set("userRole", user.Role)
+				c.Next()
+				return
+			}
+		}
+
+		logrus.Warnf("用户 %d 无权访问 %s", user.ID, c.FullPath())
+		c.JSON(http.StatusForbidden, gin.H{"error": "没有权限执行此操作"})
+		c.Abort()
+	}
+}
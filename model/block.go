@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Block 用户屏蔽关系，被屏蔽的用户不能评论屏蔽者的文章、回复其评论或提及其本人
+type Block struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_blocks_pair,priority:1;index:idx_blocks_user_created,priority:1" json:"user_id"` // 屏蔽者
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_blocks_pair,priority:2;index" json:"blocked_id"`
+	Blocked   User      `gorm:"foreignKey:BlockedID" json:"blocked,omitempty"`
+	CreatedAt time.Time `gorm:"index:idx_blocks_user_created,priority:2" json:"created_at"`
+}
+
+// Mute 用户隐藏关系，被隐藏用户的文章和评论不出现在隐藏者的列表中，对方不会察觉
+type Mute struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_mutes_pair,priority:1;index:idx_mutes_user_created,priority:1" json:"user_id"` // 隐藏者
+	MutedID   uint      `gorm:"not null;uniqueIndex:idx_mutes_pair,priority:2" json:"muted_id"`
+	Muted     User      `gorm:"foreignKey:MutedID" json:"muted,omitempty"`
+	CreatedAt time.Time `gorm:"index:idx_mutes_user_created,priority:2" json:"created_at"`
+}
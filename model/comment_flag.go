@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// CommentFlag 评论举报记录，每个用户对同一条评论只能举报一次
+type CommentFlag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CommentID uint      `gorm:"not null;uniqueIndex:idx_flag_comment_user,priority:1" json:"comment_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_flag_comment_user,priority:2" json:"user_id"`
+	Reason    string    `gorm:"size:500;not null" json:"reason"`
+	Resolved  bool      `gorm:"not null;default:false;index" json:"resolved"` // 版主处理后标记为已处理
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// CommentRevision 评论的历史版本，每次编辑前保存原内容
+type CommentRevision struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CommentID uint      `gorm:"not null;index" json:"comment_id"`
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	EditedBy  uint      `gorm:"not null" json:"edited_by"`
+	CreatedAt time.Time `json:"created_at"` // 被替换的时间
+}
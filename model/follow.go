@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Follow 用户关注关系，FollowerID 关注 FolloweeID
+type Follow struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	FollowerID uint      `gorm:"not null;uniqueIndex:idx_follows_pair,priority:1;index:idx_follows_follower_created,priority:1" json:"follower_id"`
+	Follower   User      `gorm:"foreignKey:FollowerID" json:"follower,omitempty"`
+	FolloweeID uint      `gorm:"not null;uniqueIndex:idx_follows_pair,priority:2;index:idx_follows_followee_created,priority:1" json:"followee_id"`
+	Followee   User      `gorm:"foreignKey:FolloweeID" json:"followee,omitempty"`
+	CreatedAt  time.Time `gorm:"index:idx_follows_follower_created,priority:2;index:idx_follows_followee_created,priority:2" json:"created_at"` // 关注列表和粉丝列表按 (created_at, id) 游标分页
+}
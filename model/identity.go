@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// UserIdentity 外部身份模型，关联第三方身份提供方账号与本地用户
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Provider  string    `gorm:"size:50;not null;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject   string    `gorm:"size:191;not null;uniqueIndex:idx_provider_subject" json:"subject"`
+	Email     string    `gorm:"size:100" json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
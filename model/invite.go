@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InviteCode 邀请码模型，邀请注册模式下使用
+type InviteCode struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Code      string         `gorm:"size:32;uniqueIndex;not null" json:"code"`
+	CreatedBy uint           `gorm:"not null;index" json:"created_by"`
+	MaxUses   int            `gorm:"not null" json:"max_uses"` // 0 表示不限次数
+	UsedCount int            `gorm:"not null;default:0" json:"used_count"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Media 媒体文件模型，记录上传者及存储位置
+type Media struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserID    uint           `gorm:"not null;index" json:"user_id"`
+	Key       string         `gorm:"size:255;uniqueIndex;not null" json:"-"` // 存储中的文件名
+	URL       string         `gorm:"size:500;not null;index:,length:191" json:"url"`
+	Filename  string         `gorm:"size:255" json:"filename"` // 上传时的原始文件名
+	MimeType  string         `gorm:"size:100;not null" json:"mime_type"`
+	Size      int64          `gorm:"not null" json:"size"`
+	Width     int            `json:"width,omitempty"`
+	Height    int            `json:"height,omitempty"`
+	Variants  []MediaVariant `gorm:"foreignKey:MediaID" json:"variants,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// MediaVariant 图片的缩放版本
+type MediaVariant struct {
+	ID      uint   `gorm:"primaryKey" json:"-"`
+	MediaID uint   `gorm:"not null;index" json:"-"`
+	Name    string `gorm:"size:50;not null" json:"name"`
+	Key     string `gorm:"size:255;not null" json:"-"`
+	URL     string `gorm:"size:500;not null;index:,length:191" json:"url"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Size    int64  `json:"size"`
+}
+
+// PostMedia 文章引用的媒体文件
+type PostMedia struct {
+	PostID    uint      `gorm:"primaryKey" json:"post_id"`
+	MediaID   uint      `gorm:"primaryKey;index" json:"media_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -2,9 +2,9 @@ package model
 
 import (
 	"blog-backend/config"
+	"blog-backend/utils"
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -19,6 +19,8 @@ type User struct {
 	Username  string         `gorm:"size:50;uniqueIndex;not null" json:"username"`
 	Password  string         `gorm:"size:100;not null" json:"-"` // 不在JSON中显示密码
 	Email     string         `gorm:"size:100;uniqueIndex;not null" json:"email"`
+	Role      string         `gorm:"size:20;not null;default:user" json:"role"`
+	Status    string         `gorm:"size:20;not null;default:active;index" json:"status"`
 	Posts     []Post         `gorm:"foreignKey:UserID" json:"posts,omitempty"`
 	Comments  []Comment      `gorm:"foreignKey:UserID" json:"comments,omitempty"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -26,43 +28,97 @@ type User struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// 用户角色
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// 用户状态
+const (
+	UserStatusActive   = "active"
+	UserStatusPending  = "pending"
+	UserStatusRejected = "rejected"
+)
+
 // Post 文章模型
 type Post struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Title     string         `gorm:"size:100;not null" json:"title"`
-	Content   string         `gorm:"type:text;not null" json:"content"`
-	UserID    uint           `gorm:"not null" json:"user_id"`
-	User      User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Comments  []Comment      `gorm:"foreignKey:PostID" json:"comments,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                   uint           `gorm:"primaryKey" json:"id"`
+	Title                string         `gorm:"size:100;not null" json:"title"`
+	Slug                 string         `gorm:"size:191;index" json:"slug"`
+	Content              string         `gorm:"type:text;not null" json:"content"`
+	ContentHTML          string         `gorm:"type:mediumtext" json:"content_html"` // 渲染后的HTML缓存
+	Visibility           string         `gorm:"size:20;not null;default:public;index" json:"visibility"`
+	Password             string         `gorm:"size:100" json:"-"`                                 // 密码保护文章的访问密码哈希
+	Version              uint           `gorm:"not null;default:1" json:"version"`                 // 乐观锁版本号，每次更新加一
+	CommentMode          string         `gorm:"size:20;not null;default:open" json:"comment_mode"` // 评论模式: open、disabled 或 locked
+	CommentAutoCloseDays *int           `json:"comment_auto_close_days"`                           // 发布多少天后自动关闭评论，为空时使用站点默认值，0 表示不自动关闭
+	UserID               uint           `gorm:"not null;index:idx_posts_user_created,priority:1" json:"user_id"`
+	User                 User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Comments             []Comment      `gorm:"foreignKey:PostID" json:"comments,omitempty"`
+	CreatedAt            time.Time      `gorm:"index:idx_posts_created;index:idx_posts_user_created,priority:2" json:"created_at"` // 游标分页按 (created_at, id) 排序，二级索引隐含主键
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// 文章可见性
+const (
+	VisibilityPublic   = "public"   // 公开
+	VisibilityUnlisted = "unlisted" // 不公开列出，可通过链接访问
+	VisibilityPrivate  = "private"  // 仅作者和管理员可见
+	VisibilityPassword = "password" // 需要密码访问
+)
+
+// PostSlug 文章 slug 记录，包含当前及历史 slug，用于旧链接跳转
+type PostSlug struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PostID    uint      `gorm:"not null;index" json:"post_id"`
+	Slug      string    `gorm:"size:191;uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // Comment 评论模型
 type Comment struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Content   string         `gorm:"type:text;not null" json:"content"`
-	UserID    uint           `gorm:"not null" json:"user_id"`
-	User      User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	PostID    uint           `gorm:"not null" json:"post_id"`
-	Post      Post           `gorm:"foreignKey:PostID" json:"post,omitempty"`
-	CreatedAt time.Time      `json:"created_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Content     string         `gorm:"type:text;not null" json:"content"`
+	ContentHTML string         `gorm:"type:text" json:"content_html"` // 渲染后的HTML缓存
+	UserID      uint           `gorm:"not null" json:"user_id"`
+	User        User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	PostID      uint           `gorm:"not null;index:idx_comments_post_created,priority:1" json:"post_id"`
+	Post        Post           `gorm:"foreignKey:PostID" json:"post,omitempty"`
+	ParentID    *uint          `gorm:"index" json:"parent_id,omitempty"` // 回复的评论ID，顶层评论为空
+	CreatedAt   time.Time      `gorm:"index:idx_comments_post_created,priority:2" json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	EditedAt    *time.Time     `json:"edited_at,omitempty"` // 作者最后一次编辑内容的时间，未编辑过为空
+	Status      string         `gorm:"size:20;not null;default:approved;index" json:"status"`
+	Flags       []CommentFlag  `gorm:"foreignKey:CommentID" json:"flags,omitempty"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-// BeforeSave - 保存前的钩子，用于密码加密
-func (u *User) BeforeSave(tx *gorm.DB) error {
-	if len(u.Password) > 0 {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return err
-		}
-		u.Password = string(hashedPassword)
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusSpam     = "spam"
+)
+
+// SetPassword 加密并设置密码，Password 字段只保存哈希
+func (u *User) SetPassword(password string) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
 	}
+	u.Password = hashedPassword
 	return nil
 }
 
+// CheckPassword 校验密码，needsRehash 表示哈希需要升级
+func (u *User) CheckPassword(password string) (ok bool, needsRehash bool) {
+	return utils.VerifyPassword(u.Password, password)
+}
+
 // InitDB 初始化数据库连接
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	dsn := cfg.DBUser + ":" + cfg.DBPassword + "@tcp(" + cfg.DBHost + ":" + cfg.DBPort + ")/" + cfg.DBName + "?charset=" + cfg.DBCharset + "&parseTime=True&loc=Local"
@@ -92,5 +148,25 @@ func AutoMigrate(db *gorm.DB) error {
 		&User{},
 		&Post{},
 		&Comment{},
+		&CommentRevision{},
+		&CommentFlag{},
+		&SpamToken{},
+		&Notification{},
+		&NotificationPreference{},
+		&PostSlug{},
+		&Session{},
+		&StreamTicket{},
+		&UserIdentity{},
+		&InviteCode{},
+		&Media{},
+		&MediaVariant{},
+		&PostMedia{},
+		&Reaction{},
+		&Bookmark{},
+		&Follow{},
+		&Block{},
+		&Mute{},
+		&PostViewDaily{},
+		&PostReferrer{},
 	)
 }
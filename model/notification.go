@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// 通知类型
+const (
+	NotificationMention = "mention" // 在文章或评论中被提及
+	NotificationComment = "comment" // 自己的文章收到新评论
+	NotificationReply   = "reply"   // 自己的评论收到回复
+)
+
+// Notification 站内通知
+type Notification struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index:idx_notifications_user_created,priority:1" json:"user_id"` // 接收者
+	ActorID   uint       `gorm:"not null" json:"actor_id"`                                                // 触发者
+	Actor     User       `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	Type      string     `gorm:"size:20;not null" json:"type"`
+	PostID    uint       `gorm:"not null;index" json:"post_id"`
+	CommentID *uint      `gorm:"index" json:"comment_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+}
+
+// NotificationPreference 用户的通知偏好，没有记录时所有类型都接收
+type NotificationPreference struct {
+	UserID    uint      `gorm:"primaryKey" json:"-"`
+	Mention   bool      `gorm:"not null" json:"mention"`
+	Comment   bool      `gorm:"not null" json:"comment"`
+	Reply     bool      `gorm:"not null" json:"reply"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Enabled 判断是否接收某类通知
+func (p *NotificationPreference) Enabled(notificationType string) bool {
+	switch notificationType {
+	case NotificationMention:
+		return p.Mention
+	case NotificationComment:
+		return p.Comment
+	case NotificationReply:
+		return p.Reply
+	}
+	return false
+}
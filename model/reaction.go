@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Reaction 文章表态，每个用户对同一文章的每种表态只能有一条
+type Reaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_reaction_post_user_type,priority:1" json:"post_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_reaction_post_user_type,priority:2;index" json:"user_id"`
+	Type      string    `gorm:"size:32;not null;uniqueIndex:idx_reaction_post_user_type,priority:3" json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Bookmark 文章收藏，仅收藏者本人可见
+type Bookmark struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_bookmark_user_post,priority:1" json:"user_id"`
+	PostID    uint      `gorm:"not null;uniqueIndex:idx_bookmark_user_post,priority:2;index" json:"post_id"`
+	Post      Post      `gorm:"foreignKey:PostID" json:"post,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Session 登录会话模型，每次登录生成一条记录
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	SessionID  string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // 写入JWT的会话标识
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	UserAgent  string     `gorm:"size:255" json:"user_agent"`
+	IP         string     `gorm:"size:45" json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+}
+
+// StreamTicket 实时推送的一次性票据，EventSource 无法设置请求头，用票据代替查询参数中的JWT
+type StreamTicket struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	Ticket    string    `gorm:"size:64;uniqueIndex;not null" json:"ticket"`
+	UserID    uint      `gorm:"not null" json:"-"`
+	SessionID string    `gorm:"size:64;not null" json:"-"` // 签发票据的会话，会话注销后票据失效
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// SpamToken 贝叶斯分类器的词频，记录包含该词的垃圾评论数和正常评论数。
+// Token 为空字符串的记录保存两类评论的总数
+type SpamToken struct {
+	Token string `gorm:"size:128;primaryKey" json:"token"`
+	Spam  int64  `gorm:"not null;default:0" json:"spam"`
+	Ham   int64  `gorm:"not null;default:0" json:"ham"`
+}
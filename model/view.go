@@ -0,0 +1,16 @@
+package model
+
+// PostViewDaily 文章每日阅读数
+type PostViewDaily struct {
+	PostID uint   `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	Day    string `gorm:"primaryKey;size:10;index" json:"day"` // 格式 2006-01-02
+	Views  int64  `gorm:"not null;default:0" json:"views"`
+}
+
+// PostReferrer 文章每日来源统计，Referrer 为来源域名，直接访问时为空
+type PostReferrer struct {
+	PostID   uint   `gorm:"primaryKey;autoIncrement:false" json:"post_id"`
+	Day      string `gorm:"primaryKey;size:10" json:"day"`
+	Referrer string `gorm:"primaryKey;size:191" json:"referrer"`
+	Views    int64  `gorm:"not null;default:0" json:"views"`
+}
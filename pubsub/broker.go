@@ -0,0 +1,65 @@
+package pubsub
+
+import (
+	"blog-backend/config"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Broker 消息代理接口。多实例部署时，各实例通过同一个代理互相转发事件
+type Broker interface {
+	// Publish 发布消息到指定主题
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe 注册消息处理函数，接收所有主题的消息，处理函数不能阻塞
+	Subscribe(handler func(topic string, payload []byte)) error
+	// Close 关闭代理连接
+	Close() error
+}
+
+// NewBroker 根据配置创建消息代理实例
+func NewBroker(cfg *config.Config) (Broker, error) {
+	switch cfg.PubSubBroker {
+	case "memory":
+		return NewMemoryBroker(), nil
+	default:
+		return nil, fmt.Errorf("不支持的消息代理类型: %s", cfg.PubSubBroker)
+	}
+}
+
+// MemoryBroker 进程内消息代理，只适用于单实例部署
+type MemoryBroker struct {
+	mu       sync.RWMutex
+	handlers []func(topic string, payload []byte)
+}
+
+// NewMemoryBroker 创建进程内消息代理实例
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Publish 同步调用所有处理函数
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, payload []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.handlers {
+		handler(topic, payload)
+	}
+	return nil
+}
+
+// Subscribe 注册消息处理函数
+func (b *MemoryBroker) Subscribe(handler func(topic string, payload []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+// Close 移除所有处理函数
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = nil
+	return nil
+}
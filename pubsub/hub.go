@@ -0,0 +1,161 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event 推送给订阅者的事件
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Hub 本实例的订阅中心，事件经消息代理发布，再分发给本实例中订阅了该主题的连接
+type Hub struct {
+	broker     Broker
+	bufferSize int
+
+	mu     sync.Mutex
+	topics map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub 创建订阅中心，bufferSize 为每个订阅者的事件缓冲数
+func NewHub(broker Broker, bufferSize int) (*Hub, error) {
+	if bufferSize <= 0 {
+		bufferSize = 64
+	}
+	h := &Hub{
+		broker:     broker,
+		bufferSize: bufferSize,
+		topics:     make(map[string]map[*Subscription]struct{}),
+	}
+	if err := broker.Subscribe(h.dispatch); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Publish 发布事件，data 序列化为 JSON。发布失败只记录日志，不影响业务流程
+func (h *Hub) Publish(topic, eventType string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		logrus.Errorf("序列化事件 %s 失败: %v", eventType, err)
+		return
+	}
+	payload, err := json.Marshal(Event{Type: eventType, Data: raw})
+	if err != nil {
+		logrus.Errorf("序列化事件 %s 失败: %v", eventType, err)
+		return
+	}
+	if err := h.broker.Publish(context.Background(), topic, payload); err != nil {
+		logrus.Errorf("发布事件 %s 到 %s 失败: %v", eventType, topic, err)
+	}
+}
+
+// Subscribe 订阅主题，使用完毕后必须调用 Close
+func (h *Hub) Subscribe(topic string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, errors.New("推送服务已关闭")
+	}
+
+	sub := &Subscription{hub: h, topic: topic, events: make(chan Event, h.bufferSize)}
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Subscription]struct{})
+	}
+	h.topics[topic][sub] = struct{}{}
+	return sub, nil
+}
+
+// Close 关闭所有订阅和消息代理，用于服务器退出时结束长连接
+func (h *Hub) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	for _, subs := range h.topics {
+		for sub := range subs {
+			close(sub.events)
+		}
+	}
+	h.topics = nil
+	h.mu.Unlock()
+
+	if err := h.broker.Close(); err != nil {
+		logrus.Errorf("关闭消息代理失败: %v", err)
+	}
+}
+
+// dispatch 将消息代理收到的事件分发给本实例的订阅者。
+// 订阅者的缓冲区已满时不等待，直接断开该订阅者，避免慢连接拖慢其他订阅者
+func (h *Hub) dispatch(topic string, payload []byte) {
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		logrus.Warnf("无法解析主题 %s 的事件: %v", topic, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.topics[topic] {
+		select {
+		case sub.events <- event:
+		default:
+			logrus.Warnf("主题 %s 的订阅者处理过慢，断开连接", topic)
+			sub.lagged = true
+			h.remove(sub)
+		}
+	}
+}
+
+// remove 移除并关闭订阅，调用方需持有锁
+func (h *Hub) remove(sub *Subscription) {
+	subs, ok := h.topics[sub.topic]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.topics, sub.topic)
+	}
+	close(sub.events)
+}
+
+// Subscription 对一个主题的订阅
+type Subscription struct {
+	hub    *Hub
+	topic  string
+	events chan Event
+	lagged bool
+}
+
+// Events 返回事件通道，订阅被关闭后通道关闭
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Lagged 订阅是否因处理过慢被断开，需要在通道关闭后调用
+func (s *Subscription) Lagged() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.lagged
+}
+
+// Close 取消订阅，可以重复调用
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
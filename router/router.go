@@ -4,6 +4,8 @@ import (
 	"blog-backend/config"
 	"blog-backend/controller"
 	"blog-backend/middleware"
+	"blog-backend/model"
+	"blog-backend/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,45 +15,185 @@ func SetupRouter(
 	userController *controller.UserController,
 	postController *controller.PostController,
 	commentController *controller.CommentController,
+	sessionController *controller.SessionController,
+	oauthController *controller.OAuthController,
+	adminController *controller.AdminController,
+	mediaController *controller.MediaController,
+	feedController *controller.FeedController,
+	sitemapController *controller.SitemapController,
+	reactionController *controller.ReactionController,
+	analyticsController *controller.AnalyticsController,
+	trashController *controller.TrashController,
+	moderationController *controller.ModerationController,
+	notificationController *controller.NotificationController,
+	streamController *controller.StreamController,
+	followController *controller.FollowController,
+	blockController *controller.BlockController,
+	userService service.UserService,
+	sessionService service.SessionService,
 	cfg *config.Config,
 ) *gin.Engine {
 	// 设置Gin模式
 	gin.SetMode(cfg.GinMode)
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(middleware.Logger(), gin.Recovery())
+
+	// 本地存储的上传文件
+	if cfg.StorageDriver == "local" {
+		r.Static(cfg.UploadBaseURL, cfg.UploadDir)
+	}
+
+	// 订阅源
+	for _, format := range []string{"rss", "atom", "json"} {
+		r.GET("/feed."+format, feedController.SiteFeed)
+		r.GET("/authors/:id/feed."+format, feedController.AuthorFeed)
+	}
+
+	// 站点地图
+	r.GET("/sitemap.xml", sitemapController.Sitemap)
+	r.GET("/sitemap/:page", sitemapController.SitemapPage)
+	r.GET("/robots.txt", sitemapController.RobotsTxt)
 
 	// API路由组
 	api := r.Group("/api")
 	{
 		// 公共路由
 		public := api.Group("")
+		public.Use(middleware.OptionalAuthMiddleware(cfg, sessionService))
 		{
 			// 用户相关
 			public.POST("/register", userController.Register)
 			public.POST("/login", userController.Login)
 			public.GET("/users/:id", userController.GetUser)
+			public.GET("/users/:id/followers", followController.ListFollowers)
+			public.GET("/users/:id/following", followController.ListFollowing)
+
+			// 第三方登录
+			public.GET("/oauth/:provider/login", oauthController.Login)
+			public.GET("/oauth/:provider/callback", oauthController.Callback)
 
 			// 文章相关
 			public.GET("/posts", postController.ListPosts)
 			public.GET("/posts/:id", postController.GetPost)
+			public.GET("/posts/by-slug/:slug", postController.GetPostBySlug)
+			public.POST("/posts/:id/unlock", postController.UnlockPost)
 			public.GET("/users-posts/:user_id/posts", postController.GetUserPosts)
 
+			// 表态相关
+			public.GET("/reaction-types", reactionController.ListReactionTypes)
+
 			// 评论相关
 			public.GET("/posts-comments/:post_id/comments", commentController.GetPostComments)
 		}
 
+		// 实时推送，EventSource 无法设置请求头，允许通过一次性票据认证
+		api.GET("/posts/:id/stream", middleware.StreamAuthMiddleware(cfg, sessionService, false), streamController.PostStream)
+		api.GET("/notifications/stream", middleware.StreamAuthMiddleware(cfg, sessionService, true), streamController.NotificationStream)
+
 		// 需要认证的路由
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg))
+		protected.Use(middleware.AuthMiddleware(cfg, sessionService))
 		{
+			// 用户相关
+			protected.PUT("/users/me/password", userController.ChangePassword)
+
+			// 关注相关
+			protected.POST("/users/:id/follow", followController.Follow)
+			protected.DELETE("/users/:id/follow", followController.Unfollow)
+			protected.GET("/feed", followController.Feed)
+
+			// 屏蔽与隐藏相关
+			protected.POST("/users/:id/block", blockController.Block)
+			protected.DELETE("/users/:id/block", blockController.Unblock)
+			protected.POST("/users/:id/mute", blockController.Mute)
+			protected.DELETE("/users/:id/mute", blockController.Unmute)
+			protected.GET("/blocks", blockController.ListBlocked)
+			protected.GET("/mutes", blockController.ListMuted)
+
+			// 会话相关
+			protected.GET("/sessions", sessionController.ListSessions)
+			protected.DELETE("/sessions", sessionController.RevokeOtherSessions)
+			protected.DELETE("/sessions/:id", sessionController.RevokeSession)
+			protected.POST("/stream-tickets", sessionController.CreateStreamTicket)
+
 			// 文章相关
 			protected.POST("/posts", postController.CreatePost)
 			protected.PUT("/posts/:id", postController.UpdatePost)
+			protected.PATCH("/posts/:id", postController.PatchPost)
 			protected.DELETE("/posts/:id", postController.DeletePost)
+			protected.PUT("/posts/:id/comment-settings", postController.UpdateCommentSettings)
+
+			// 表态与收藏相关
+			protected.PUT("/posts/:id/reactions/:type", reactionController.AddReaction)
+			protected.DELETE("/posts/:id/reactions/:type", reactionController.RemoveReaction)
+			protected.POST("/posts/:id/bookmark", reactionController.AddBookmark)
+			protected.DELETE("/posts/:id/bookmark", reactionController.RemoveBookmark)
+			protected.GET("/bookmarks", reactionController.ListBookmarks)
+
+			// 阅读统计相关
+			protected.GET("/analytics", analyticsController.Dashboard)
+			protected.GET("/posts/:id/analytics", analyticsController.PostAnalytics)
+
+			// 回收站相关
+			protected.GET("/trash/posts", trashController.ListPosts)
+			protected.POST("/trash/posts/:id/restore", trashController.RestorePost)
+			protected.DELETE("/trash/posts/:id", trashController.PurgePost)
+			protected.GET("/trash/comments", trashController.ListComments)
+			protected.POST("/trash/comments/:id/restore", trashController.RestoreComment)
+			protected.DELETE("/trash/comments/:id", trashController.PurgeComment)
+
+			// 媒体文件相关
+			protected.POST("/media", mediaController.Upload)
+			protected.GET("/media", mediaController.ListMedia)
+			protected.GET("/media/:id", mediaController.GetMedia)
+			protected.DELETE("/media/:id", mediaController.DeleteMedia)
 
 			// 评论相关
 			protected.POST("/posts-comments/:post_id/comments", commentController.CreateComment)
+			protected.PATCH("/comments/:id", commentController.PatchComment)
 			protected.DELETE("/comments/:id", commentController.DeleteComment)
+			protected.POST("/comments/:id/flag", moderationController.FlagComment)
+
+			// 通知相关
+			protected.GET("/notifications", notificationController.ListNotifications)
+			protected.GET("/notifications/unread-count", notificationController.UnreadCount)
+			protected.POST("/notifications/:id/read", notificationController.MarkRead)
+			protected.POST("/notifications/read-all", notificationController.MarkAllRead)
+			protected.GET("/notifications/preferences", notificationController.GetPreferences)
+			protected.PUT("/notifications/preferences", notificationController.UpdatePreferences)
+		}
+
+		// 审核路由（版主及管理员）
+		moderation := api.Group("/admin")
+		moderation.Use(middleware.AuthMiddleware(cfg, sessionService), middleware.RequireRole(userService, model.RoleModerator, model.RoleAdmin))
+		{
+			moderation.GET("/users/pending", adminController.ListPendingUsers)
+			moderation.POST("/users/:id/approve", adminController.ApproveUser)
+			moderation.POST("/users/:id/reject", adminController.RejectUser)
+			moderation.GET("/comments/:id/revisions", commentController.ListRevisions)
+			moderation.GET("/comments/queue", moderationController.ListQueue)
+			moderation.POST("/comments/:id/approve", moderationController.ApproveComment)
+			moderation.POST("/comments/:id/reject", moderationController.RejectComment)
+			moderation.POST("/comments/:id/spam", moderationController.MarkSpam)
+		}
+
+		// 管理路由（仅管理员）
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(cfg, sessionService), middleware.RequireRole(userService, model.RoleAdmin))
+		{
+			admin.POST("/invites", adminController.CreateInvite)
+			admin.GET("/invites", adminController.ListInvites)
+			admin.DELETE("/invites/:id", adminController.DeleteInvite)
+			admin.PUT("/users/:id/role", adminController.SetUserRole)
+
+			// 回收站（所有用户的内容）
+			admin.GET("/trash/posts", trashController.ListPosts)
+			admin.POST("/trash/posts/:id/restore", trashController.RestorePost)
+			admin.DELETE("/trash/posts/:id", trashController.PurgePost)
+			admin.GET("/trash/comments", trashController.ListComments)
+			admin.POST("/trash/comments/:id/restore", trashController.RestoreComment)
+			admin.DELETE("/trash/comments/:id", trashController.PurgeComment)
 		}
 	}
 
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"blog-backend/model"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlockService 屏蔽与隐藏服务接口
+type BlockService interface {
+	Block(userID, targetID uint) error
+	Unblock(userID, targetID uint) error
+	ListBlocked(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Block, *CursorPage, error)
+	Mute(userID, targetID uint) error
+	Unmute(userID, targetID uint) error
+	ListMuted(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Mute, *CursorPage, error)
+	HiddenUserIDs(userID uint) (map[uint]bool, error)
+}
+
+// blockService 屏蔽与隐藏服务实现
+type blockService struct {
+	db *gorm.DB
+}
+
+// NewBlockService 创建屏蔽与隐藏服务实例
+func NewBlockService(db *gorm.DB) BlockService {
+	return &blockService{db: db}
+}
+
+// Block 屏蔽用户，重复屏蔽不报错；同时解除双方的关注关系
+func (s *blockService) Block(userID, targetID uint) error {
+	if userID == targetID {
+		return errors.New("不能屏蔽自己")
+	}
+	if err := checkActiveUser(s.db, targetID); err != nil {
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		block := model.Block{UserID: userID, BlockedID: targetID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+		return tx.Where("(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)",
+			userID, targetID, targetID, userID).Delete(&model.Follow{}).Error
+	})
+	if err != nil {
+		logrus.Errorf("用户 %d 屏蔽用户 %d 失败: %v", userID, targetID, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 屏蔽用户 %d", userID, targetID)
+	return nil
+}
+
+// Unblock 取消屏蔽，未屏蔽时不报错
+func (s *blockService) Unblock(userID, targetID uint) error {
+	if err := s.db.Where("user_id = ? AND blocked_id = ?", userID, targetID).Delete(&model.Block{}).Error; err != nil {
+		logrus.Errorf("用户 %d 取消屏蔽用户 %d 失败: %v", userID, targetID, err)
+		return err
+	}
+	return nil
+}
+
+// ListBlocked 获取用户屏蔽的用户列表（游标分页，按屏蔽时间倒序）
+func (s *blockService) ListBlocked(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Block, *CursorPage, error) {
+	query := s.db.Model(&model.Block{}).Preload("Blocked").Where("user_id = ?", userID)
+	blocks, page, err := paginateByCursor(query, cursor, pageSize, withTotal, func(b *model.Block) (time.Time, uint) {
+		return b.CreatedAt, b.ID
+	})
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的屏蔽列表失败: %v", userID, err)
+	}
+	return blocks, page, err
+}
+
+// Mute 隐藏用户，重复隐藏不报错
+func (s *blockService) Mute(userID, targetID uint) error {
+	if userID == targetID {
+		return errors.New("不能隐藏自己")
+	}
+	if err := checkActiveUser(s.db, targetID); err != nil {
+		return err
+	}
+
+	mute := model.Mute{UserID: userID, MutedID: targetID}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error; err != nil {
+		logrus.Errorf("用户 %d 隐藏用户 %d 失败: %v", userID, targetID, err)
+		return err
+	}
+	return nil
+}
+
+// Unmute 取消隐藏，未隐藏时不报错
+func (s *blockService) Unmute(userID, targetID uint) error {
+	if err := s.db.Where("user_id = ? AND muted_id = ?", userID, targetID).Delete(&model.Mute{}).Error; err != nil {
+		logrus.Errorf("用户 %d 取消隐藏用户 %d 失败: %v", userID, targetID, err)
+		return err
+	}
+	return nil
+}
+
+// ListMuted 获取用户隐藏的用户列表（游标分页，按隐藏时间倒序）
+func (s *blockService) ListMuted(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Mute, *CursorPage, error) {
+	query := s.db.Model(&model.Mute{}).Preload("Muted").Where("user_id = ?", userID)
+	mutes, page, err := paginateByCursor(query, cursor, pageSize, withTotal, func(m *model.Mute) (time.Time, uint) {
+		return m.CreatedAt, m.ID
+	})
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的隐藏列表失败: %v", userID, err)
+	}
+	return mutes, page, err
+}
+
+// HiddenUserIDs 获取用户屏蔽或隐藏的所有用户ID
+func (s *blockService) HiddenUserIDs(userID uint) (map[uint]bool, error) {
+	var blocked, muted []uint
+	if err := s.db.Model(&model.Block{}).Where("user_id = ?", userID).Pluck("blocked_id", &blocked).Error; err != nil {
+		logrus.Errorf("获取用户 %d 屏蔽的用户失败: %v", userID, err)
+		return nil, err
+	}
+	if err := s.db.Model(&model.Mute{}).Where("user_id = ?", userID).Pluck("muted_id", &muted).Error; err != nil {
+		logrus.Errorf("获取用户 %d 隐藏的用户失败: %v", userID, err)
+		return nil, err
+	}
+
+	hidden := make(map[uint]bool, len(blocked)+len(muted))
+	for _, id := range append(blocked, muted...) {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// isBlocked 判断 blockerID 是否屏蔽了 userID
+func isBlocked(db *gorm.DB, blockerID, userID uint) bool {
+	if blockerID == 0 || userID == 0 || blockerID == userID {
+		return false
+	}
+	var count int64
+	db.Model(&model.Block{}).Where("user_id = ? AND blocked_id = ?", blockerID, userID).Count(&count)
+	return count > 0
+}
+
+// isMuted 判断 userID 是否隐藏了 mutedID
+func isMuted(db *gorm.DB, userID, mutedID uint) bool {
+	if userID == 0 || mutedID == 0 || userID == mutedID {
+		return false
+	}
+	var count int64
+	db.Model(&model.Mute{}).Where("user_id = ? AND muted_id = ?", userID, mutedID).Count(&count)
+	return count > 0
+}
+
+// hiddenAuthors 排除访问者屏蔽或隐藏的用户发布的内容，column 为作者ID列，viewerID 为 0 时不过滤
+func hiddenAuthors(viewerID uint, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		sub := db.Session(&gorm.Session{NewDB: true})
+		return db.
+			Where(column+" NOT IN (?)", sub.Model(&model.Block{}).Select("blocked_id").Where("user_id = ?", viewerID)).
+			Where(column+" NOT IN (?)", sub.Model(&model.Mute{}).Select("muted_id").Where("user_id = ?", viewerID))
+	}
+}
@@ -1,33 +1,63 @@
 package service
 
 import (
+	"blog-backend/config"
 	"blog-backend/model"
+	"blog-backend/pubsub"
+	"blog-backend/spam"
+	"blog-backend/utils"
 	"errors"
+	"time"
 
-	"gorm.io/gorm"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // CommentService 评论服务接口
 type CommentService interface {
-	CreateComment(content string, userID, postID uint) (*model.Comment, error)
+	CreateComment(content string, userID, postID, parentID uint, userAgent, ip string) (*model.Comment, error)
 	GetCommentByID(id uint) (*model.Comment, error)
-	GetPostComments(postID uint, page, pageSize int) ([]model.Comment, int64, error)
+	GetPostComments(postID, viewerID uint, page, pageSize int) ([]model.Comment, int64, error)
+	GetPostCommentsByCursor(postID, viewerID uint, cursor string, pageSize int, withTotal bool) ([]model.Comment, *CursorPage, error)
+	PatchComment(id, userID uint, patch CommentPatch, userAgent, ip string) (*model.Comment, error)
+	ListRevisions(id uint) ([]model.CommentRevision, error)
 	DeleteComment(id uint, userID uint) error
+	BackfillContentHTML() error
 }
 
 // commentService 评论服务实现
 type commentService struct {
-	db *gorm.DB
+	db                  *gorm.DB
+	spamService         SpamService
+	notificationService NotificationService
+	hub                 *pubsub.Hub
+	editWindow          time.Duration // 发布后允许编辑的时间，0 表示不限制
+	moderation          string        // 评论审核模式
+	autoCloseDays       int           // 站点默认的评论自动关闭天数
 }
 
 // NewCommentService 创建评论服务实例
-func NewCommentService(db *gorm.DB) CommentService {
-	return &commentService{db: db}
+func NewCommentService(db *gorm.DB, cfg *config.Config, spamService SpamService, notificationService NotificationService, hub *pubsub.Hub) CommentService {
+	editWindow, err := time.ParseDuration(cfg.CommentEditWindow)
+	if err != nil || editWindow < 0 {
+		logrus.Warnf("无效的评论编辑时间 %q，使用默认值15m", cfg.CommentEditWindow)
+		editWindow = 15 * time.Minute
+	}
+	return &commentService{
+		db:                  db,
+		spamService:         spamService,
+		notificationService: notificationService,
+		hub:                 hub,
+		editWindow:          editWindow,
+		moderation:          cfg.CommentModeration,
+		autoCloseDays:       cfg.CommentAutoCloseDays,
+	}
 }
 
-// CreateComment 创建评论
-func (s *commentService) CreateComment(content string, userID, postID uint) (*model.Comment, error) {
+// CreateComment 创建评论，parentID 不为 0 时为回复同一文章下的评论。
+// 文章评论未开放或被文章作者、回复的评论作者屏蔽时不能评论；
+// 经过垃圾内容检查和审核模式确定评论状态，评论公开后发送通知
+func (s *commentService) CreateComment(content string, userID, postID, parentID uint, userAgent, ip string) (*model.Comment, error) {
 	// 检查文章是否存在
 	var post model.Post
 	if err := s.db.First(&post, postID).Error; err != nil {
@@ -35,11 +65,36 @@ func (s *commentService) CreateComment(content string, userID, postID uint) (*mo
 		return nil, errors.New("文章不存在")
 	}
 
+	if !commentState(&post, s.autoCloseDays, time.Now()).Open() {
+		return nil, errors.New("评论已关闭")
+	}
+	if isBlocked(s.db, post.UserID, userID) {
+		return nil, errors.New("对方已将你屏蔽")
+	}
+
+	// 检查回复的评论
+	var parent *uint
+	if parentID != 0 {
+		var parentComment model.Comment
+		if err := s.db.Select("id", "user_id").
+			Where("post_id = ? AND status = ?", postID, model.CommentStatusApproved).
+			First(&parentComment, parentID).Error; err != nil {
+			return nil, errors.New("回复的评论不存在")
+		}
+		if isBlocked(s.db, parentComment.UserID, userID) {
+			return nil, errors.New("对方已将你屏蔽")
+		}
+		parent = &parentID
+	}
+
 	// 创建评论
 	comment := &model.Comment{
-		Content: content,
-		UserID:  userID,
-		PostID:  postID,
+		Content:     content,
+		ContentHTML: utils.RenderCommentMarkdown(content),
+		UserID:      userID,
+		PostID:      postID,
+		ParentID:    parent,
+		Status:      s.initialStatus(content, userID, userAgent, ip),
 	}
 
 	if err := s.db.Create(comment).Error; err != nil {
@@ -47,10 +102,43 @@ func (s *commentService) CreateComment(content string, userID, postID uint) (*mo
 		return nil, err
 	}
 
-	logrus.Infof("用户 %d 为文章 %d 创建评论成功", userID, postID)
+	logrus.Infof("用户 %d 为文章 %d 创建评论成功，状态: %s", userID, postID, comment.Status)
+	if comment.Status == model.CommentStatusApproved {
+		s.notificationService.NotifyComment(comment)
+		publishComment(s.db, s.hub, EventCommentCreated, comment)
+	}
 	return comment, nil
 }
 
+// initialStatus 根据垃圾内容检查结果和审核模式确定新评论的状态，版主和管理员的评论无需检查
+func (s *commentService) initialStatus(content string, userID uint, userAgent, ip string) string {
+	var user model.User
+	if err := s.db.Select("role").First(&user, userID).Error; err == nil &&
+		(user.Role == model.RoleModerator || user.Role == model.RoleAdmin) {
+		return model.CommentStatusApproved
+	}
+
+	switch s.spamService.CheckComment(content, userID, userAgent, ip).Action {
+	case spam.ActionSpam:
+		return model.CommentStatusSpam
+	case spam.ActionReview:
+		return model.CommentStatusPending
+	}
+
+	if s.moderation == config.CommentModerationOff {
+		return model.CommentStatusApproved
+	}
+	if s.moderation == config.CommentModerationFirstTime {
+		// 已有通过审核的评论则不再审核
+		var approved int64
+		s.db.Model(&model.Comment{}).Where("user_id = ? AND status = ?", userID, model.CommentStatusApproved).Count(&approved)
+		if approved > 0 {
+			return model.CommentStatusApproved
+		}
+	}
+	return model.CommentStatusPending
+}
+
 // GetCommentByID 根据ID获取评论
 func (s *commentService) GetCommentByID(id uint) (*model.Comment, error) {
 	var comment model.Comment
@@ -61,8 +149,9 @@ func (s *commentService) GetCommentByID(id uint) (*model.Comment, error) {
 	return &comment, nil
 }
 
-// GetPostComments 获取文章的评论列表
-func (s *commentService) GetPostComments(postID uint, page, pageSize int) ([]model.Comment, int64, error) {
+// GetPostComments 获取文章已通过审核的评论列表，不包含访问者屏蔽或隐藏的用户的评论；
+// 禁用评论的文章返回空列表
+func (s *commentService) GetPostComments(postID, viewerID uint, page, pageSize int) ([]model.Comment, int64, error) {
 	var comments []model.Comment
 	var total int64
 
@@ -72,9 +161,14 @@ func (s *commentService) GetPostComments(postID uint, page, pageSize int) ([]mod
 		logrus.Errorf("获取评论失败: 文章 %d 不存在 - %v", postID, err)
 		return nil, 0, errors.New("文章不存在")
 	}
+	if post.CommentMode == config.CommentsDisabled {
+		return nil, 0, nil
+	}
 
 	// 计算总记录数
-	if err := s.db.Model(&model.Comment{}).Where("post_id = ?", postID).Count(&total).Error; err != nil {
+	query := s.db.Model(&model.Comment{}).Scopes(hiddenAuthors(viewerID, "user_id")).
+		Where("post_id = ? AND status = ?", postID, model.CommentStatusApproved)
+	if err := query.Count(&total).Error; err != nil {
 		logrus.Errorf("计算文章 %d 的评论总数失败: %v", postID, err)
 		return nil, 0, err
 	}
@@ -83,7 +177,7 @@ func (s *commentService) GetPostComments(postID uint, page, pageSize int) ([]mod
 	offset := (page - 1) * pageSize
 
 	// 获取分页数据
-	if err := s.db.Preload("User").Where("post_id = ?", postID).Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&comments).Error; err != nil {
+	if err := query.Preload("User").Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&comments).Error; err != nil {
 		logrus.Errorf("获取文章 %d 的评论列表失败: %v", postID, err)
 		return nil, 0, err
 	}
@@ -91,6 +185,130 @@ func (s *commentService) GetPostComments(postID uint, page, pageSize int) ([]mod
 	return comments, total, nil
 }
 
+// GetPostCommentsByCursor 获取文章已通过审核的评论列表（游标分页），不包含访问者屏蔽或隐藏的用户的评论；
+// 禁用评论的文章返回空列表
+func (s *commentService) GetPostCommentsByCursor(postID, viewerID uint, cursor string, pageSize int, withTotal bool) ([]model.Comment, *CursorPage, error) {
+	// 检查文章是否存在
+	var post model.Post
+	if err := s.db.First(&post, postID).Error; err != nil {
+		logrus.Errorf("获取评论失败: 文章 %d 不存在 - %v", postID, err)
+		return nil, nil, errors.New("文章不存在")
+	}
+	if post.CommentMode == config.CommentsDisabled {
+		page := &CursorPage{}
+		if withTotal {
+			var total int64
+			page.Total = &total
+		}
+		return nil, page, nil
+	}
+
+	query := s.db.Model(&model.Comment{}).Preload("User").Scopes(hiddenAuthors(viewerID, "user_id")).
+		Where("post_id = ? AND status = ?", postID, model.CommentStatusApproved)
+	comments, page, err := paginateByCursor(query, cursor, pageSize, withTotal, func(c *model.Comment) (time.Time, uint) {
+		return c.CreatedAt, c.ID
+	})
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取文章 %d 的评论列表失败: %v", postID, err)
+	}
+	return comments, page, err
+}
+
+// CommentPatch 评论部分更新的内容，字段为 nil 表示不修改
+type CommentPatch struct {
+	Content *string
+}
+
+// PatchComment 部分更新评论，只写入提供的字段；内容变化时保存旧版本并标记为已编辑，
+// 并像新评论一样重新检查，检查不通过时评论回到待审核或垃圾状态；公开的评论通知新提及的用户
+func (s *commentService) PatchComment(id, userID uint, patch CommentPatch, userAgent, ip string) (*model.Comment, error) {
+	// 检查评论是否存在
+	var comment model.Comment
+	if err := s.db.First(&comment, id).Error; err != nil {
+		logrus.Errorf("更新评论 %d 失败: 评论不存在 - %v", id, err)
+		return nil, errors.New("评论不存在")
+	}
+
+	// 检查权限
+	if comment.UserID != userID {
+		logrus.Warnf("用户 %d 尝试更新不属于自己的评论 %d", userID, id)
+		return nil, errors.New("没有权限更新此评论")
+	}
+
+	// 检查编辑时间
+	if s.editWindow > 0 && time.Since(comment.CreatedAt) > s.editWindow {
+		return nil, errors.New("评论已超过可编辑时间")
+	}
+
+	// 评论关闭或锁定后已有评论只读
+	var post model.Post
+	if err := s.db.Select("id", "comment_mode", "comment_auto_close_days", "created_at").First(&post, comment.PostID).Error; err != nil {
+		return nil, errors.New("文章不存在")
+	}
+	if !commentState(&post, s.autoCloseDays, time.Now()).Open() {
+		return nil, errors.New("评论已关闭")
+	}
+
+	if patch.Content == nil || *patch.Content == comment.Content {
+		return &comment, nil
+	}
+
+	now := time.Now()
+	revision := model.CommentRevision{CommentID: comment.ID, Content: comment.Content, EditedBy: userID}
+	wasApproved := comment.Status == model.CommentStatusApproved
+	comment.Content = *patch.Content
+	comment.ContentHTML = utils.RenderCommentMarkdown(comment.Content)
+	comment.EditedAt = &now
+	// 编辑不会让未通过审核的评论自动公开
+	if status := s.initialStatus(comment.Content, userID, userAgent, ip); status != model.CommentStatusApproved {
+		comment.Status = status
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		return tx.Model(&comment).Updates(map[string]interface{}{
+			"content":      comment.Content,
+			"content_html": comment.ContentHTML,
+			"edited_at":    now,
+			"status":       comment.Status,
+		}).Error
+	})
+	if err != nil {
+		logrus.Errorf("更新评论 %d 失败: %v", id, err)
+		return nil, err
+	}
+
+	logrus.Infof("用户 %d 更新评论成功: %d，状态: %s", userID, id, comment.Status)
+	if comment.Status == model.CommentStatusApproved {
+		s.notificationService.NotifyCommentMentions(&comment, revision.Content)
+		publishComment(s.db, s.hub, EventCommentUpdated, &comment)
+	} else if wasApproved {
+		// 评论被撤回审核，从实时推送中移除
+		publishComment(s.db, s.hub, EventCommentDeleted, &comment)
+	}
+	return &comment, nil
+}
+
+// ListRevisions 获取评论的历史版本（按时间倒序），已删除的评论也可查看
+func (s *commentService) ListRevisions(id uint) ([]model.CommentRevision, error) {
+	var count int64
+	if err := s.db.Unscoped().Model(&model.Comment{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("评论不存在")
+	}
+
+	var revisions []model.CommentRevision
+	if err := s.db.Where("comment_id = ?", id).Order("id DESC").Find(&revisions).Error; err != nil {
+		logrus.Errorf("获取评论 %d 的历史版本失败: %v", id, err)
+		return nil, err
+	}
+	return revisions, nil
+}
+
 // DeleteComment 删除评论
 func (s *commentService) DeleteComment(id uint, userID uint) error {
 	// 检查评论是否存在
@@ -113,5 +331,29 @@ func (s *commentService) DeleteComment(id uint, userID uint) error {
 	}
 
 	logrus.Infof("用户 %d 删除评论成功: %d", userID, id)
+	if comment.Status == model.CommentStatusApproved {
+		publishComment(s.db, s.hub, EventCommentDeleted, &comment)
+	}
+	return nil
+}
+
+// BackfillContentHTML 为缺少HTML缓存的历史评论渲染内容
+func (s *commentService) BackfillContentHTML() error {
+	var comments []model.Comment
+	if err := s.db.Where("content_html = '' OR content_html IS NULL").Find(&comments).Error; err != nil {
+		logrus.Errorf("查询缺少HTML缓存的评论失败: %v", err)
+		return err
+	}
+
+	for _, comment := range comments {
+		if err := s.db.Model(&comment).UpdateColumn("content_html", utils.RenderCommentMarkdown(comment.Content)).Error; err != nil {
+			logrus.Errorf("渲染评论 %d 的HTML失败: %v", comment.ID, err)
+			return err
+		}
+	}
+
+	if len(comments) > 0 {
+		logrus.Infof("已为 %d 条评论渲染HTML", len(comments))
+	}
 	return nil
 }
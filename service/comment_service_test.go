@@ -0,0 +1,71 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/pubsub"
+	"testing"
+)
+
+// TestPatchCommentRechecksContent 编辑后命中屏蔽词的评论应变为垃圾评论，并从实时推送中移除
+func TestPatchCommentRechecksContent(t *testing.T) {
+	db := newTestDB(t)
+	cfg := &config.Config{
+		CommentModeration: config.CommentModerationOff,
+		CommentEditWindow: "15m",
+		SpamKeywords:      "casino",
+	}
+	hub, err := pubsub.NewHub(pubsub.NewMemoryBroker(), 0)
+	if err != nil {
+		t.Fatalf("创建推送服务失败: %v", err)
+	}
+	defer hub.Close()
+	comments := NewCommentService(db, cfg, NewSpamService(db, cfg), NewNotificationService(db, hub), hub)
+
+	user := model.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: model.RoleUser, Status: model.UserStatusActive}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	post := model.Post{Title: "hello", Content: "hello", UserID: user.ID}
+	if err := db.Create(&post).Error; err != nil {
+		t.Fatalf("创建文章失败: %v", err)
+	}
+	comment, err := comments.CreateComment("nice post", user.ID, post.ID, 0, "", "")
+	if err != nil {
+		t.Fatalf("创建评论失败: %v", err)
+	}
+	if comment.Status != model.CommentStatusApproved {
+		t.Fatalf("评论状态 = %s, 期望 %s", comment.Status, model.CommentStatusApproved)
+	}
+
+	sub, err := hub.Subscribe(PostTopic(post.ID))
+	if err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	defer sub.Close()
+
+	content := "cheap casino"
+	patched, err := comments.PatchComment(comment.ID, user.ID, CommentPatch{Content: &content}, "", "")
+	if err != nil {
+		t.Fatalf("更新评论失败: %v", err)
+	}
+	if patched.Status != model.CommentStatusSpam {
+		t.Errorf("评论状态 = %s, 期望 %s", patched.Status, model.CommentStatusSpam)
+	}
+	var stored model.Comment
+	if err := db.First(&stored, comment.ID).Error; err != nil {
+		t.Fatalf("加载评论失败: %v", err)
+	}
+	if stored.Status != model.CommentStatusSpam {
+		t.Errorf("保存的评论状态 = %s, 期望 %s", stored.Status, model.CommentStatusSpam)
+	}
+
+	select {
+	case event := <-sub.Events():
+		if event.Type != EventCommentDeleted {
+			t.Errorf("推送事件 = %s, 期望 %s", event.Type, EventCommentDeleted)
+		}
+	default:
+		t.Error("没有推送评论删除事件")
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"time"
+)
+
+// CommentsClosed 评论因超过自动关闭时间而关闭，已有评论只读
+const CommentsClosed = "closed"
+
+// CommentState 文章评论的当前状态
+type CommentState struct {
+	Status   string     `json:"status"`    // open、disabled、locked 或 closed
+	ClosesAt *time.Time `json:"closes_at"` // 自动关闭评论的时间，不自动关闭时为空
+}
+
+// Open 是否允许发表和编辑评论
+func (s CommentState) Open() bool {
+	return s.Status == config.CommentsOpen
+}
+
+// commentState 根据文章的评论模式和自动关闭天数计算评论状态，
+// 文章未单独设置自动关闭天数时使用 defaultDays
+func commentState(post *model.Post, defaultDays int, now time.Time) CommentState {
+	mode := post.CommentMode
+	if mode == "" {
+		mode = config.CommentsOpen
+	}
+	if mode != config.CommentsOpen {
+		return CommentState{Status: mode}
+	}
+
+	days := defaultDays
+	if post.CommentAutoCloseDays != nil {
+		days = *post.CommentAutoCloseDays
+	}
+	if days <= 0 {
+		return CommentState{Status: mode}
+	}
+
+	closesAt := post.CreatedAt.AddDate(0, 0, days)
+	if !now.Before(closesAt) {
+		return CommentState{Status: CommentsClosed, ClosesAt: &closesAt}
+	}
+	return CommentState{Status: mode, ClosesAt: &closesAt}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"blog-backend/model"
+	"blog-backend/pubsub"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 实时推送的事件类型
+const (
+	EventCommentCreated = "comment.created"
+	EventCommentUpdated = "comment.updated"
+	EventCommentDeleted = "comment.deleted"
+	EventNotification   = "notification"
+)
+
+// PostTopic 文章评论的推送主题
+func PostTopic(postID uint) string {
+	return "post:" + strconv.FormatUint(uint64(postID), 10)
+}
+
+// UserTopic 用户通知的推送主题
+func UserTopic(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// CommentEvent 评论推送事件的内容，字段与评论列表一致
+type CommentEvent struct {
+	ID          uint       `json:"id"`
+	Content     string     `json:"content,omitempty"`
+	ContentHTML string     `json:"content_html,omitempty"`
+	ParentID    *uint      `json:"parent_id,omitempty"`
+	UserID      uint       `json:"user_id,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+}
+
+// publishComment 推送评论的新增、更新或删除事件。新增和更新只推送已公开的评论，
+// 删除事件（包括被隐藏）只包含评论ID，由调用方确认评论此前是公开的
+func publishComment(db *gorm.DB, hub *pubsub.Hub, eventType string, comment *model.Comment) {
+	event := CommentEvent{ID: comment.ID}
+	if eventType != EventCommentDeleted {
+		if comment.Status != model.CommentStatusApproved {
+			return
+		}
+		var user model.User
+		db.Select("username").First(&user, comment.UserID)
+		event.Content = comment.Content
+		event.ContentHTML = comment.ContentHTML
+		event.ParentID = comment.ParentID
+		event.UserID = comment.UserID
+		event.Username = user.Username
+		event.CreatedAt = &comment.CreatedAt
+		event.EditedAt = comment.EditedAt
+	}
+	hub.Publish(PostTopic(comment.PostID), eventType, event)
+}
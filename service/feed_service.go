@@ -0,0 +1,106 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/utils"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/feeds"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// FeedService 订阅源服务接口
+type FeedService interface {
+	SiteFeed() (*feeds.Feed, error)
+	AuthorFeed(userID uint) (*feeds.Feed, error)
+}
+
+// feedService 订阅源服务实现
+type feedService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewFeedService 创建订阅源服务实例
+func NewFeedService(db *gorm.DB, cfg *config.Config) FeedService {
+	return &feedService{db: db, cfg: cfg}
+}
+
+// SiteFeed 全站订阅源
+func (s *feedService) SiteFeed() (*feeds.Feed, error) {
+	var posts []model.Post
+	if err := s.db.Preload("User").Where("visibility = ?", model.VisibilityPublic).Order("created_at DESC").Limit(s.cfg.FeedItemLimit).Find(&posts).Error; err != nil {
+		logrus.Errorf("获取订阅源文章失败: %v", err)
+		return nil, err
+	}
+
+	feed := &feeds.Feed{
+		Title:       s.cfg.SiteTitle,
+		Link:        &feeds.Link{Href: utils.AbsoluteURL(s.cfg, "/")},
+		Description: s.cfg.SiteDescription,
+		Id:          utils.AbsoluteURL(s.cfg, "/feed.atom"),
+	}
+	s.fillItems(feed, posts)
+	return feed, nil
+}
+
+// AuthorFeed 作者订阅源
+func (s *feedService) AuthorFeed(userID uint) (*feeds.Feed, error) {
+	var user model.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		logrus.Warnf("获取作者订阅源失败: 用户 %d 不存在 - %v", userID, err)
+		return nil, errors.New("用户不存在")
+	}
+
+	var posts []model.Post
+	if err := s.db.Preload("User").Where("user_id = ? AND visibility = ?", userID, model.VisibilityPublic).Order("created_at DESC").Limit(s.cfg.FeedItemLimit).Find(&posts).Error; err != nil {
+		logrus.Errorf("获取用户 %d 的订阅源文章失败: %v", userID, err)
+		return nil, err
+	}
+
+	id := strconv.FormatUint(uint64(userID), 10)
+	feed := &feeds.Feed{
+		Title:       s.cfg.SiteTitle + " - " + user.Username,
+		Link:        &feeds.Link{Href: utils.AbsoluteURL(s.cfg, "/api/users/"+id)},
+		Description: s.cfg.SiteDescription,
+		Author:      &feeds.Author{Name: user.Username},
+		Id:          utils.AbsoluteURL(s.cfg, "/authors/"+id+"/feed.atom"),
+	}
+	s.fillItems(feed, posts)
+	return feed, nil
+}
+
+// fillItems 将文章转换为订阅条目，按配置输出全文或摘要
+func (s *feedService) fillItems(feed *feeds.Feed, posts []model.Post) {
+	var updated time.Time
+	for _, post := range posts {
+		link := utils.PostURL(s.cfg, post.ID, post.Slug)
+		item := &feeds.Item{
+			Id:          link,
+			Title:       post.Title,
+			Link:        &feeds.Link{Href: link},
+			Author:      &feeds.Author{Name: post.User.Username},
+			Description: utils.Excerpt(post.ContentHTML, s.cfg.FeedExcerptLength),
+			Created:     post.CreatedAt,
+			Updated:     post.UpdatedAt,
+		}
+		if s.cfg.FeedFullContent {
+			item.Content = post.ContentHTML
+		}
+		feed.Items = append(feed.Items, item)
+
+		if post.UpdatedAt.After(updated) {
+			updated = post.UpdatedAt
+		}
+	}
+
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	feed.Created = updated
+	feed.Updated = updated
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"blog-backend/model"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowStats 用户的关注统计
+type FollowStats struct {
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+	Following      bool  `json:"is_following"` // 当前访问者是否已关注该用户
+}
+
+// FollowService 关注服务接口
+type FollowService interface {
+	Follow(followerID, followeeID uint) error
+	Unfollow(followerID, followeeID uint) error
+	ListFollowers(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Follow, *CursorPage, error)
+	ListFollowing(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Follow, *CursorPage, error)
+	GetFollowStats(userID, viewerID uint) (*FollowStats, error)
+	Feed(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Post, *CursorPage, error)
+}
+
+// followService 关注服务实现
+type followService struct {
+	db *gorm.DB
+}
+
+// NewFollowService 创建关注服务实例
+func NewFollowService(db *gorm.DB) FollowService {
+	return &followService{db: db}
+}
+
+// Follow 关注用户，重复关注不报错；被对方屏蔽时不能关注
+func (s *followService) Follow(followerID, followeeID uint) error {
+	if followerID == followeeID {
+		return errors.New("不能关注自己")
+	}
+	if err := checkActiveUser(s.db, followeeID); err != nil {
+		return err
+	}
+	if isBlocked(s.db, followeeID, followerID) {
+		return errors.New("对方已将你屏蔽")
+	}
+
+	follow := model.Follow{FollowerID: followerID, FolloweeID: followeeID}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow).Error; err != nil {
+		logrus.Errorf("用户 %d 关注用户 %d 失败: %v", followerID, followeeID, err)
+		return err
+	}
+	return nil
+}
+
+// Unfollow 取消关注，未关注时不报错
+func (s *followService) Unfollow(followerID, followeeID uint) error {
+	if err := s.db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&model.Follow{}).Error; err != nil {
+		logrus.Errorf("用户 %d 取消关注用户 %d 失败: %v", followerID, followeeID, err)
+		return err
+	}
+	return nil
+}
+
+// ListFollowers 获取用户的粉丝列表（游标分页，按关注时间倒序）
+func (s *followService) ListFollowers(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Follow, *CursorPage, error) {
+	if err := checkActiveUser(s.db, userID); err != nil {
+		return nil, nil, err
+	}
+	query := s.db.Model(&model.Follow{}).Preload("Follower").Where("followee_id = ?", userID)
+	follows, page, err := paginateByCursor(query, cursor, pageSize, withTotal, followCursorKey)
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的粉丝列表失败: %v", userID, err)
+	}
+	return follows, page, err
+}
+
+// ListFollowing 获取用户关注的用户列表（游标分页，按关注时间倒序）
+func (s *followService) ListFollowing(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Follow, *CursorPage, error) {
+	if err := checkActiveUser(s.db, userID); err != nil {
+		return nil, nil, err
+	}
+	query := s.db.Model(&model.Follow{}).Preload("Followee").Where("follower_id = ?", userID)
+	follows, page, err := paginateByCursor(query, cursor, pageSize, withTotal, followCursorKey)
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的关注列表失败: %v", userID, err)
+	}
+	return follows, page, err
+}
+
+// GetFollowStats 获取用户的粉丝数、关注数以及访问者是否已关注，viewerID 为 0 表示未登录
+func (s *followService) GetFollowStats(userID, viewerID uint) (*FollowStats, error) {
+	var stats FollowStats
+	if err := s.db.Model(&model.Follow{}).Where("followee_id = ?", userID).Count(&stats.FollowerCount).Error; err != nil {
+		logrus.Errorf("统计用户 %d 的粉丝数失败: %v", userID, err)
+		return nil, err
+	}
+	if err := s.db.Model(&model.Follow{}).Where("follower_id = ?", userID).Count(&stats.FollowingCount).Error; err != nil {
+		logrus.Errorf("统计用户 %d 的关注数失败: %v", userID, err)
+		return nil, err
+	}
+	if viewerID != 0 && viewerID != userID {
+		var count int64
+		if err := s.db.Model(&model.Follow{}).Where("follower_id = ? AND followee_id = ?", viewerID, userID).Count(&count).Error; err != nil {
+			logrus.Errorf("查询用户 %d 是否关注用户 %d 失败: %v", viewerID, userID, err)
+			return nil, err
+		}
+		stats.Following = count > 0
+	}
+	return &stats, nil
+}
+
+// Feed 获取关注的作者发布的文章（游标分页，按时间倒序），不包含未列出的文章及已屏蔽或隐藏的作者
+func (s *followService) Feed(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Post, *CursorPage, error) {
+	followees := s.db.Model(&model.Follow{}).Select("followee_id").Where("follower_id = ?", userID)
+	query := s.db.Model(&model.Post{}).Scopes(listedPosts, hiddenAuthors(userID, "user_id")).Preload("User").
+		Where("user_id IN (?)", followees)
+	posts, page, err := paginateByCursor(query, cursor, pageSize, withTotal, postCursorKey)
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的关注动态失败: %v", userID, err)
+	}
+	return posts, page, err
+}
+
+// followCursorKey 关注关系的游标排序键
+func followCursorKey(f *model.Follow) (time.Time, uint) {
+	return f.CreatedAt, f.ID
+}
+
+// checkActiveUser 检查用户是否存在且已激活
+func checkActiveUser(db *gorm.DB, userID uint) error {
+	var count int64
+	if err := db.Model(&model.User{}).Where("id = ? AND status = ?", userID, model.UserStatusActive).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("用户不存在")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"blog-backend/model"
+	"blog-backend/utils"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// InviteService 邀请码服务接口
+type InviteService interface {
+	CreateInvite(createdBy uint, maxUses int, validFor time.Duration) (*model.InviteCode, error)
+	ListInvites(page, pageSize int) ([]model.InviteCode, int64, error)
+	DeleteInvite(id uint) error
+}
+
+// inviteService 邀请码服务实现
+type inviteService struct {
+	db *gorm.DB
+}
+
+// NewInviteService 创建邀请码服务实例
+func NewInviteService(db *gorm.DB) InviteService {
+	return &inviteService{db: db}
+}
+
+// CreateInvite 创建邀请码，validFor 为 0 时永不过期
+func (s *inviteService) CreateInvite(createdBy uint, maxUses int, validFor time.Duration) (*model.InviteCode, error) {
+	code, err := utils.GenerateRandomString(8)
+	if err != nil {
+		logrus.Errorf("生成邀请码失败: %v", err)
+		return nil, err
+	}
+
+	invite := &model.InviteCode{
+		Code:      strings.ToUpper(code),
+		CreatedBy: createdBy,
+		MaxUses:   maxUses,
+	}
+	if validFor > 0 {
+		expiresAt := time.Now().Add(validFor)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	if err := s.db.Create(invite).Error; err != nil {
+		logrus.Errorf("创建邀请码失败: %v", err)
+		return nil, err
+	}
+
+	logrus.Infof("用户 %d 创建邀请码成功: %d", createdBy, invite.ID)
+	return invite, nil
+}
+
+// ListInvites 获取邀请码列表（分页）
+func (s *inviteService) ListInvites(page, pageSize int) ([]model.InviteCode, int64, error) {
+	var invites []model.InviteCode
+	var total int64
+
+	// 计算总记录数
+	if err := s.db.Model(&model.InviteCode{}).Count(&total).Error; err != nil {
+		logrus.Errorf("计算邀请码总数失败: %v", err)
+		return nil, 0, err
+	}
+
+	// 计算偏移量
+	offset := (page - 1) * pageSize
+
+	// 获取分页数据
+	if err := s.db.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&invites).Error; err != nil {
+		logrus.Errorf("获取邀请码列表失败: %v", err)
+		return nil, 0, err
+	}
+
+	return invites, total, nil
+}
+
+// DeleteInvite 作废邀请码
+func (s *inviteService) DeleteInvite(id uint) error {
+	var invite model.InviteCode
+	if err := s.db.First(&invite, id).Error; err != nil {
+		logrus.Errorf("作废邀请码 %d 失败: 邀请码不存在 - %v", id, err)
+		return errors.New("邀请码不存在")
+	}
+
+	if err := s.db.Delete(&invite).Error; err != nil {
+		logrus.Errorf("作废邀请码 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("邀请码已作废: %d", id)
+	return nil
+}
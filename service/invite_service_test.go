@@ -0,0 +1,41 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"testing"
+)
+
+// TestUnlimitedInvite 可使用次数为 0 的邀请码不限次数，不应被存储为默认值
+func TestUnlimitedInvite(t *testing.T) {
+	db := newTestDB(t)
+	cfg := &config.Config{
+		RegistrationMode:  config.RegistrationInvite,
+		PasswordMinLength: 8,
+	}
+	users := NewUserService(db, cfg, NewSpamService(db, cfg))
+
+	invite, err := NewInviteService(db).CreateInvite(1, 0, 0)
+	if err != nil {
+		t.Fatalf("创建邀请码失败: %v", err)
+	}
+	var stored model.InviteCode
+	if err := db.First(&stored, invite.ID).Error; err != nil {
+		t.Fatalf("加载邀请码失败: %v", err)
+	}
+	if stored.MaxUses != 0 {
+		t.Fatalf("邀请码可使用次数 = %d, 期望 0", stored.MaxUses)
+	}
+
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := users.CreateUser(name, name+"@example.com", "s3cret-pass", invite.Code, "", ""); err != nil {
+			t.Fatalf("用户 %s 使用邀请码注册失败: %v", name, err)
+		}
+	}
+	if err := db.First(&stored, invite.ID).Error; err != nil {
+		t.Fatalf("加载邀请码失败: %v", err)
+	}
+	if stored.UsedCount != 2 {
+		t.Errorf("邀请码已使用次数 = %d, 期望 2", stored.UsedCount)
+	}
+}
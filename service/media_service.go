@@ -0,0 +1,382 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/storage"
+	"blog-backend/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // 注册 WebP 解码器
+	"gorm.io/gorm"
+)
+
+// maxImagePixels 允许处理的最大图片像素数，防止解压炸弹
+const maxImagePixels = 50_000_000
+
+// allowedMediaTypes 允许上传的文件类型及扩展名，类型由文件内容嗅探得出
+var allowedMediaTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// mediaURLPattern 从文章内容中提取链接及图片地址
+var mediaURLPattern = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)|(?:src|href)\s*=\s*["']([^"']+)["']`)
+
+// imageVariant 缩略图规格
+type imageVariant struct {
+	name     string
+	maxWidth int
+}
+
+// MediaService 媒体文件服务接口
+type MediaService interface {
+	Upload(ctx context.Context, userID uint, filename string, file io.Reader) (*model.Media, error)
+	GetMediaByID(id uint) (*model.Media, []uint, error)
+	ListUserMedia(userID uint, page, pageSize int) ([]model.Media, int64, int64, error)
+	DeleteMedia(ctx context.Context, id uint, userID uint) error
+}
+
+// mediaService 媒体文件服务实现
+type mediaService struct {
+	db       *gorm.DB
+	cfg      *config.Config
+	storage  storage.Storage
+	variants []imageVariant
+}
+
+// NewMediaService 创建媒体文件服务实例
+func NewMediaService(db *gorm.DB, cfg *config.Config, store storage.Storage) MediaService {
+	var variants []imageVariant
+	for _, spec := range strings.Split(cfg.MediaImageVariants, ",") {
+		name, width, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		if !ok {
+			continue
+		}
+		w, err := strconv.Atoi(width)
+		if err != nil || w <= 0 {
+			logrus.Warnf("忽略无效的缩略图规格: %s", spec)
+			continue
+		}
+		variants = append(variants, imageVariant{name: name, maxWidth: w})
+	}
+
+	return &mediaService{db: db, cfg: cfg, storage: store, variants: variants}
+}
+
+// Upload 上传文件，图片会自动生成缩略图
+func (s *mediaService) Upload(ctx context.Context, userID uint, filename string, file io.Reader) (*model.Media, error) {
+	// 读取文件内容，多读一个字节用于判断是否超过大小限制
+	data, err := io.ReadAll(io.LimitReader(file, s.cfg.MediaMaxSize+1))
+	if err != nil {
+		logrus.Errorf("读取上传文件失败: %v", err)
+		return nil, err
+	}
+	if int64(len(data)) > s.cfg.MediaMaxSize {
+		logrus.Warnf("用户 %d 上传的文件超过大小限制", userID)
+		return nil, errors.New("文件大小超过限制")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("文件为空")
+	}
+
+	// 根据文件内容判断类型，不信任客户端提供的类型
+	mimeType := http.DetectContentType(data)
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	ext, ok := allowedMediaTypes[mimeType]
+	if !ok {
+		logrus.Warnf("用户 %d 上传了不支持的文件类型: %s", userID, mimeType)
+		return nil, errors.New("不支持的文件类型")
+	}
+
+	// 检查用户容量
+	used, err := s.usedQuota(userID)
+	if err != nil {
+		return nil, err
+	}
+	if used+int64(len(data)) > s.cfg.MediaUserQuota {
+		logrus.Warnf("用户 %d 的媒体容量不足: 已用 %d", userID, used)
+		return nil, errors.New("存储空间不足")
+	}
+
+	random, err := utils.GenerateRandomString(12)
+	if err != nil {
+		return nil, err
+	}
+	baseKey := time.Now().Format("2006/01") + "/" + random
+
+	media := &model.Media{
+		UserID:   userID,
+		Key:      baseKey + ext,
+		URL:      s.storage.URL(baseKey + ext),
+		Filename: filepath.Base(filename),
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+	}
+
+	// 图片生成缩略图
+	var variantData [][]byte
+	if strings.HasPrefix(mimeType, "image/") {
+		variants, encoded, width, height, err := s.resizeImage(data, mimeType, baseKey)
+		if err != nil {
+			logrus.Warnf("用户 %d 上传的图片无法解析: %v", userID, err)
+			return nil, errors.New("无效的图片文件")
+		}
+		media.Width, media.Height = width, height
+		media.Variants = variants
+		variantData = encoded
+	}
+
+	// 保存文件
+	var stored []string
+	cleanup := func() {
+		for _, key := range stored {
+			if err := s.storage.Delete(ctx, key); err != nil {
+				logrus.Errorf("清理文件 %s 失败: %v", key, err)
+			}
+		}
+	}
+	if err := s.storage.Put(ctx, media.Key, bytes.NewReader(data), media.Size, mimeType); err != nil {
+		logrus.Errorf("保存文件失败: %v", err)
+		return nil, err
+	}
+	stored = append(stored, media.Key)
+	for i, variant := range media.Variants {
+		contentType := "image/jpeg"
+		if strings.HasSuffix(variant.Key, ".png") {
+			contentType = "image/png"
+		}
+		if err := s.storage.Put(ctx, variant.Key, bytes.NewReader(variantData[i]), variant.Size, contentType); err != nil {
+			logrus.Errorf("保存缩略图失败: %v", err)
+			cleanup()
+			return nil, err
+		}
+		stored = append(stored, variant.Key)
+	}
+
+	if err := s.db.Create(media).Error; err != nil {
+		logrus.Errorf("保存媒体记录失败: %v", err)
+		cleanup()
+		return nil, err
+	}
+
+	logrus.Infof("用户 %d 上传文件成功: %d", userID, media.ID)
+	return media, nil
+}
+
+// GetMediaByID 获取媒体文件及引用它的文章ID
+func (s *mediaService) GetMediaByID(id uint) (*model.Media, []uint, error) {
+	var media model.Media
+	if err := s.db.Preload("Variants").First(&media, id).Error; err != nil {
+		logrus.Errorf("获取媒体文件 %d 失败: %v", id, err)
+		return nil, nil, errors.New("文件不存在")
+	}
+
+	var postIDs []uint
+	if err := s.db.Model(&model.PostMedia{}).Where("media_id = ?", id).Pluck("post_id", &postIDs).Error; err != nil {
+		logrus.Errorf("获取媒体文件 %d 的引用失败: %v", id, err)
+		return nil, nil, err
+	}
+
+	return &media, postIDs, nil
+}
+
+// ListUserMedia 获取用户上传的文件列表（分页），同时返回已用容量
+func (s *mediaService) ListUserMedia(userID uint, page, pageSize int) ([]model.Media, int64, int64, error) {
+	var media []model.Media
+	var total int64
+
+	// 计算总记录数
+	if err := s.db.Model(&model.Media{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		logrus.Errorf("计算用户 %d 的文件总数失败: %v", userID, err)
+		return nil, 0, 0, err
+	}
+
+	// 计算偏移量
+	offset := (page - 1) * pageSize
+
+	// 获取分页数据
+	if err := s.db.Preload("Variants").Where("user_id = ?", userID).Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&media).Error; err != nil {
+		logrus.Errorf("获取用户 %d 的文件列表失败: %v", userID, err)
+		return nil, 0, 0, err
+	}
+
+	used, err := s.usedQuota(userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return media, total, used, nil
+}
+
+// DeleteMedia 删除文件，被文章引用的文件不能删除
+func (s *mediaService) DeleteMedia(ctx context.Context, id uint, userID uint) error {
+	var media model.Media
+	if err := s.db.Preload("Variants").First(&media, id).Error; err != nil {
+		logrus.Errorf("删除文件 %d 失败: 文件不存在 - %v", id, err)
+		return errors.New("文件不存在")
+	}
+
+	// 检查权限
+	if media.UserID != userID {
+		logrus.Warnf("用户 %d 尝试删除不属于自己的文件 %d", userID, id)
+		return errors.New("没有权限删除此文件")
+	}
+
+	var refs int64
+	if err := s.db.Model(&model.PostMedia{}).Where("media_id = ?", id).Count(&refs).Error; err != nil {
+		return err
+	}
+	if refs > 0 {
+		logrus.Warnf("文件 %d 正被 %d 篇文章引用，无法删除", id, refs)
+		return errors.New("文件正被文章引用")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", id).Delete(&model.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&media).Error
+	})
+	if err != nil {
+		logrus.Errorf("删除文件 %d 失败: %v", id, err)
+		return err
+	}
+
+	// 删除存储中的文件，失败只记录日志
+	keys := []string{media.Key}
+	for _, variant := range media.Variants {
+		keys = append(keys, variant.Key)
+	}
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logrus.Errorf("删除存储文件 %s 失败: %v", key, err)
+		}
+	}
+
+	logrus.Infof("用户 %d 删除文件成功: %d", userID, id)
+	return nil
+}
+
+// usedQuota 计算用户已使用的容量（按原始文件大小）
+func (s *mediaService) usedQuota(userID uint) (int64, error) {
+	var used int64
+	if err := s.db.Model(&model.Media{}).Where("user_id = ?", userID).Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+		logrus.Errorf("计算用户 %d 的已用容量失败: %v", userID, err)
+		return 0, err
+	}
+	return used, nil
+}
+
+// resizeImage 解析图片并按配置生成缩放版本，小于目标宽度的规格不生成
+func (s *mediaService) resizeImage(data []byte, mimeType, baseKey string) ([]model.MediaVariant, [][]byte, int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, nil, 0, 0, fmt.Errorf("图片尺寸过大: %dx%d", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+
+	var variants []model.MediaVariant
+	var encoded [][]byte
+	for _, v := range s.variants {
+		if cfg.Width <= v.maxWidth {
+			continue
+		}
+
+		width := v.maxWidth
+		height := cfg.Height * width / cfg.Width
+		if height < 1 {
+			height = 1
+		}
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+		// PNG 和 GIF 保留透明通道，其余格式输出 JPEG
+		var buf bytes.Buffer
+		ext := ".jpg"
+		if mimeType == "image/png" || mimeType == "image/gif" {
+			ext = ".png"
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil, nil, 0, 0, err
+		}
+
+		key := baseKey + "_" + v.name + ext
+		variants = append(variants, model.MediaVariant{
+			Name:   v.name,
+			Key:    key,
+			URL:    s.storage.URL(key),
+			Width:  width,
+			Height: height,
+			Size:   int64(buf.Len()),
+		})
+		encoded = append(encoded, buf.Bytes())
+	}
+
+	return variants, encoded, cfg.Width, cfg.Height, nil
+}
+
+// syncPostMedia 根据文章内容更新文章引用的媒体文件，只关联文章作者本人上传的文件
+func syncPostMedia(tx *gorm.DB, post *model.Post) error {
+	var urls []string
+	for _, match := range mediaURLPattern.FindAllStringSubmatch(post.Content, -1) {
+		if match[1] != "" {
+			urls = append(urls, match[1])
+		} else if match[2] != "" {
+			urls = append(urls, match[2])
+		}
+	}
+
+	if err := tx.Where("post_id = ?", post.ID).Delete(&model.PostMedia{}).Error; err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	var mediaIDs []uint
+	if err := tx.Model(&model.Media{}).
+		Where("url IN ? OR id IN (?)", urls, tx.Model(&model.MediaVariant{}).Select("media_id").Where("url IN ?", urls)).
+		Where("user_id = ?", post.UserID).
+		Pluck("id", &mediaIDs).Error; err != nil {
+		return err
+	}
+
+	for _, mediaID := range mediaIDs {
+		if err := tx.Create(&model.PostMedia{PostID: post.ID, MediaID: mediaID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
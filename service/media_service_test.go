@@ -0,0 +1,65 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/pubsub"
+	"blog-backend/storage"
+	"context"
+	"testing"
+)
+
+// TestForeignMediaNotLinked 文章引用他人上传的文件时不应建立关联，文件所有者仍可删除文件
+func TestForeignMediaNotLinked(t *testing.T) {
+	db := newTestDB(t)
+	cfg := &config.Config{}
+	store, err := storage.NewLocalStorage(t.TempDir(), "/uploads")
+	if err != nil {
+		t.Fatalf("创建本地存储失败: %v", err)
+	}
+	hub, err := pubsub.NewHub(pubsub.NewMemoryBroker(), 0)
+	if err != nil {
+		t.Fatalf("创建推送服务失败: %v", err)
+	}
+	defer hub.Close()
+	media := NewMediaService(db, cfg, store)
+	posts := NewPostService(db, cfg, NewNotificationService(db, hub))
+
+	var users []model.User
+	for _, name := range []string{"alice", "bob"} {
+		user := model.User{Username: name, Email: name + "@example.com", Password: "x", Role: model.RoleUser, Status: model.UserStatusActive}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+		users = append(users, user)
+	}
+	alice, bob := users[0], users[1]
+
+	file := model.Media{
+		UserID:   alice.ID,
+		Key:      "a.png",
+		URL:      "/uploads/a.png",
+		MimeType: "image/png",
+		Size:     1,
+		Variants: []model.MediaVariant{{Name: "thumb", Key: "a_thumb.png", URL: "/uploads/a_thumb.png"}},
+	}
+	if err := db.Create(&file).Error; err != nil {
+		t.Fatalf("创建文件记录失败: %v", err)
+	}
+
+	content := "![a](/uploads/a.png) ![b](/uploads/a_thumb.png)"
+	if _, err := posts.CreatePost("hello", content, bob.ID, "", ""); err != nil {
+		t.Fatalf("创建文章失败: %v", err)
+	}
+
+	var refs int64
+	if err := db.Model(&model.PostMedia{}).Where("media_id = ?", file.ID).Count(&refs).Error; err != nil {
+		t.Fatalf("统计文件引用失败: %v", err)
+	}
+	if refs != 0 {
+		t.Errorf("他人文章对文件的引用数 = %d, 期望 0", refs)
+	}
+	if err := media.DeleteMedia(context.Background(), file.ID, alice.ID); err != nil {
+		t.Errorf("删除自己的文件失败: %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/pubsub"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 审核队列类型
+const (
+	QueuePending = "pending" // 待审核及被举报自动隐藏的评论
+	QueueFlagged = "flagged" // 仍然公开但有未处理举报的评论
+	QueueSpam    = "spam"    // 被判定为垃圾的评论，用于纠正误判
+)
+
+// ModerationService 评论审核服务接口
+type ModerationService interface {
+	FlagComment(commentID, userID uint, reason string) error
+	ListQueue(queue string, page, pageSize int) ([]model.Comment, int64, error)
+	ApproveComment(id, moderatorID uint) error
+	RejectComment(id, moderatorID uint) error
+	MarkSpam(id, moderatorID uint) error
+}
+
+// moderationService 评论审核服务实现
+type moderationService struct {
+	db                  *gorm.DB
+	spamService         SpamService
+	notificationService NotificationService
+	hub                 *pubsub.Hub
+	flagThreshold       int
+}
+
+// NewModerationService 创建评论审核服务实例
+func NewModerationService(db *gorm.DB, cfg *config.Config, spamService SpamService, notificationService NotificationService, hub *pubsub.Hub) ModerationService {
+	return &moderationService{
+		db:                  db,
+		spamService:         spamService,
+		notificationService: notificationService,
+		hub:                 hub,
+		flagThreshold:       cfg.CommentFlagThreshold,
+	}
+}
+
+// FlagComment 举报评论，重复举报不报错；未处理的举报达到阈值时评论自动隐藏并进入审核队列
+func (s *moderationService) FlagComment(commentID, userID uint, reason string) error {
+	var comment model.Comment
+	if err := s.db.Where("status = ?", model.CommentStatusApproved).First(&comment, commentID).Error; err != nil {
+		return errors.New("评论不存在")
+	}
+	if comment.UserID == userID {
+		return errors.New("不能举报自己的评论")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		flag := model.CommentFlag{CommentID: commentID, UserID: userID, Reason: reason}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&flag).Error; err != nil {
+			return err
+		}
+		if s.flagThreshold <= 0 {
+			return nil
+		}
+
+		var count int64
+		if err := tx.Model(&model.CommentFlag{}).
+			Where("comment_id = ? AND resolved = ?", commentID, false).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count < int64(s.flagThreshold) {
+			return nil
+		}
+
+		logrus.Infof("评论 %d 被举报 %d 次，自动隐藏等待审核", commentID, count)
+		return tx.Model(&comment).UpdateColumn("status", model.CommentStatusPending).Error
+	})
+	if err != nil {
+		logrus.Errorf("用户 %d 举报评论 %d 失败: %v", userID, commentID, err)
+		return err
+	}
+	if comment.Status != model.CommentStatusApproved {
+		publishComment(s.db, s.hub, EventCommentDeleted, &comment)
+	}
+
+	logrus.Infof("用户 %d 举报评论 %d", userID, commentID)
+	return nil
+}
+
+// ListQueue 获取审核队列（分页，按创建时间正序），同时加载未处理的举报
+func (s *moderationService) ListQueue(queue string, page, pageSize int) ([]model.Comment, int64, error) {
+	var comments []model.Comment
+	var total int64
+
+	query := s.db.Model(&model.Comment{})
+	switch queue {
+	case QueuePending:
+		query = query.Where("status = ?", model.CommentStatusPending)
+	case QueueSpam:
+		query = query.Where("status = ?", model.CommentStatusSpam)
+	case QueueFlagged:
+		query = query.Where("status = ? AND id IN (?)", model.CommentStatusApproved,
+			s.db.Model(&model.CommentFlag{}).Select("comment_id").Where("resolved = ?", false))
+	default:
+		return nil, 0, errors.New("无效的审核队列")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		logrus.Errorf("计算审核队列总数失败: %v", err)
+		return nil, 0, err
+	}
+	if err := query.Preload("User").Preload("Flags", "resolved = ?", false).
+		Order("created_at ASC, id ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&comments).Error; err != nil {
+		logrus.Errorf("获取审核队列失败: %v", err)
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// ApproveComment 审核通过评论
+func (s *moderationService) ApproveComment(id, moderatorID uint) error {
+	return s.moderate(id, moderatorID, model.CommentStatusApproved)
+}
+
+// RejectComment 拒绝评论
+func (s *moderationService) RejectComment(id, moderatorID uint) error {
+	return s.moderate(id, moderatorID, model.CommentStatusRejected)
+}
+
+// MarkSpam 将评论标记为垃圾评论
+func (s *moderationService) MarkSpam(id, moderatorID uint) error {
+	return s.moderate(id, moderatorID, model.CommentStatusSpam)
+}
+
+// moderate 更新评论审核状态，并将该评论的举报标记为已处理。
+// 审核结果为通过或垃圾评论时，用于训练垃圾内容分类器；
+// 待审核或垃圾评论首次通过时发送通知，被举报后恢复的评论不重复通知
+func (s *moderationService) moderate(id, moderatorID uint, status string) error {
+	var comment model.Comment
+	if err := s.db.First(&comment, id).Error; err != nil {
+		logrus.Errorf("审核评论 %d 失败: 评论不存在 - %v", id, err)
+		return errors.New("评论不存在")
+	}
+	previous := comment.Status
+
+	var resolved int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.CommentFlag{}).
+			Where("comment_id = ? AND resolved = ?", id, false).
+			UpdateColumn("resolved", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		resolved = result.RowsAffected
+		return tx.Model(&comment).UpdateColumn("status", status).Error
+	})
+	if err != nil {
+		logrus.Errorf("审核评论 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("版主 %d 审核评论 %d: %s", moderatorID, id, status)
+
+	// 状态未变且没有待处理的举报时不是新的判断，不重复训练
+	if (previous != status || resolved > 0) && (status == model.CommentStatusApproved || status == model.CommentStatusSpam) {
+		s.spamService.TrainComment(&comment, status == model.CommentStatusSpam)
+	}
+	if status == model.CommentStatusApproved && (previous == model.CommentStatusSpam ||
+		previous == model.CommentStatusPending && resolved == 0) {
+		s.notificationService.NotifyComment(&comment)
+	}
+
+	// 推送评论的公开或隐藏
+	if status == model.CommentStatusApproved && previous != status {
+		publishComment(s.db, s.hub, EventCommentCreated, &comment)
+	} else if previous == model.CommentStatusApproved && status != previous {
+		publishComment(s.db, s.hub, EventCommentDeleted, &comment)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"blog-backend/model"
+	"blog-backend/pubsub"
+	"blog-backend/utils"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// NotificationPreferences 通知偏好更新，只更新提供的字段
+type NotificationPreferences struct {
+	Mention *bool `json:"mention"`
+	Comment *bool `json:"comment"`
+	Reply   *bool `json:"reply"`
+}
+
+// NotificationEvent 通知推送事件的内容，字段与通知列表一致
+type NotificationEvent struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	ActorID   uint      `json:"actor_id"`
+	Actor     string    `json:"actor"`
+	PostID    uint      `json:"post_id"`
+	CommentID *uint     `json:"comment_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationService 站内通知服务接口
+type NotificationService interface {
+	NotifyComment(comment *model.Comment)
+	NotifyPostMentions(post *model.Post, previousContent string)
+	NotifyCommentMentions(comment *model.Comment, previousContent string)
+	ListNotifications(userID uint, unreadOnly bool, cursor string, pageSize int, withTotal bool) ([]model.Notification, *CursorPage, error)
+	UnreadCount(userID uint) (int64, error)
+	MarkRead(id, userID uint) error
+	MarkAllRead(userID uint) (int64, error)
+	GetPreferences(userID uint) (*model.NotificationPreference, error)
+	UpdatePreferences(userID uint, prefs NotificationPreferences) (*model.NotificationPreference, error)
+}
+
+// notificationService 站内通知服务实现
+type notificationService struct {
+	db  *gorm.DB
+	hub *pubsub.Hub
+}
+
+// NewNotificationService 创建站内通知服务实例
+func NewNotificationService(db *gorm.DB, hub *pubsub.Hub) NotificationService {
+	return &notificationService{db: db, hub: hub}
+}
+
+// recipient 待通知的用户及通知类型
+type recipient struct {
+	userID uint
+	typ    string
+}
+
+// NotifyComment 评论公开后通知被回复的评论作者、被提及的用户和文章作者，
+// 每个用户只收到一条通知，优先级为回复、提及、评论
+func (s *notificationService) NotifyComment(comment *model.Comment) {
+	var post model.Post
+	if err := s.db.First(&post, comment.PostID).Error; err != nil {
+		return
+	}
+
+	var recipients []recipient
+	if comment.ParentID != nil {
+		var parent model.Comment
+		if err := s.db.Select("user_id").First(&parent, *comment.ParentID).Error; err == nil {
+			recipients = append(recipients, recipient{parent.UserID, model.NotificationReply})
+		}
+	}
+	for _, id := range s.mentionedUsers(comment.Content) {
+		recipients = append(recipients, recipient{id, model.NotificationMention})
+	}
+	recipients = append(recipients, recipient{post.UserID, model.NotificationComment})
+
+	s.notify(&post, comment.UserID, &comment.ID, recipients)
+}
+
+// NotifyPostMentions 通知文章中新提及的用户，previousContent 中已提及的用户不再通知
+func (s *notificationService) NotifyPostMentions(post *model.Post, previousContent string) {
+	s.notify(post, post.UserID, nil, s.newMentions(post.Content, previousContent))
+}
+
+// NotifyCommentMentions 通知编辑后的公开评论中新提及的用户，previousContent 中已提及的用户不再通知
+func (s *notificationService) NotifyCommentMentions(comment *model.Comment, previousContent string) {
+	recipients := s.newMentions(comment.Content, previousContent)
+	if len(recipients) == 0 {
+		return
+	}
+	var post model.Post
+	if err := s.db.First(&post, comment.PostID).Error; err != nil {
+		return
+	}
+	s.notify(&post, comment.UserID, &comment.ID, recipients)
+}
+
+// newMentions 返回 content 中提及、而 previousContent 中未提及的用户
+func (s *notificationService) newMentions(content, previousContent string) []recipient {
+	previous := make(map[uint]bool)
+	for _, id := range s.mentionedUsers(previousContent) {
+		previous[id] = true
+	}
+
+	var recipients []recipient
+	for _, id := range s.mentionedUsers(content) {
+		if !previous[id] {
+			recipients = append(recipients, recipient{id, model.NotificationMention})
+		}
+	}
+	return recipients
+}
+
+// ListNotifications 获取用户的通知列表（游标分页，按时间倒序）
+func (s *notificationService) ListNotifications(userID uint, unreadOnly bool, cursor string, pageSize int, withTotal bool) ([]model.Notification, *CursorPage, error) {
+	query := s.db.Model(&model.Notification{}).Scopes(visibleNotifications).Preload("Actor").Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	notifications, page, err := paginateByCursor(query, cursor, pageSize, withTotal, func(n *model.Notification) (time.Time, uint) {
+		return n.CreatedAt, n.ID
+	})
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的通知列表失败: %v", userID, err)
+	}
+	return notifications, page, err
+}
+
+// UnreadCount 获取未读通知数
+func (s *notificationService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.Notification{}).Scopes(visibleNotifications).
+		Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error; err != nil {
+		logrus.Errorf("统计用户 %d 的未读通知失败: %v", userID, err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead 将通知标记为已读，重复标记不报错
+func (s *notificationService) MarkRead(id, userID uint) error {
+	var notification model.Notification
+	if err := s.db.Where("user_id = ?", userID).First(&notification, id).Error; err != nil {
+		return errors.New("通知不存在")
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	if err := s.db.Model(&notification).UpdateColumn("read_at", time.Now()).Error; err != nil {
+		logrus.Errorf("标记通知 %d 已读失败: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// MarkAllRead 将用户的所有未读通知标记为已读，返回标记的数量
+func (s *notificationService) MarkAllRead(userID uint) (int64, error) {
+	result := s.db.Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		UpdateColumn("read_at", time.Now())
+	if result.Error != nil {
+		logrus.Errorf("标记用户 %d 的通知已读失败: %v", userID, result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetPreferences 获取用户的通知偏好，未设置时返回全部开启
+func (s *notificationService) GetPreferences(userID uint) (*model.NotificationPreference, error) {
+	prefs := model.NotificationPreference{UserID: userID, Mention: true, Comment: true, Reply: true}
+	if err := s.db.Where("user_id = ?", userID).Limit(1).Find(&prefs).Error; err != nil {
+		logrus.Errorf("获取用户 %d 的通知偏好失败: %v", userID, err)
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences 更新用户的通知偏好
+func (s *notificationService) UpdatePreferences(userID uint, update NotificationPreferences) (*model.NotificationPreference, error) {
+	prefs, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if update.Mention != nil {
+		prefs.Mention = *update.Mention
+	}
+	if update.Comment != nil {
+		prefs.Comment = *update.Comment
+	}
+	if update.Reply != nil {
+		prefs.Reply = *update.Reply
+	}
+
+	// 记录不存在时插入，存在时更新全部字段
+	if err := s.db.Save(prefs).Error; err != nil {
+		logrus.Errorf("更新用户 %d 的通知偏好失败: %v", userID, err)
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// notify 按顺序为每个接收者创建一条通知，跳过触发者本人、重复的接收者、无权查看文章的用户、
+// 屏蔽或隐藏了触发者的用户以及关闭了该类通知的用户。通知失败只记录日志
+func (s *notificationService) notify(post *model.Post, actorID uint, commentID *uint, recipients []recipient) {
+	notified := map[uint]bool{actorID: true}
+	var notifications []model.Notification
+	for _, r := range recipients {
+		if notified[r.userID] {
+			continue
+		}
+		// 私密文章只有作者可见
+		if post.Visibility == model.VisibilityPrivate && r.userID != post.UserID {
+			continue
+		}
+		// 被屏蔽的用户无法提及或打扰对方
+		if isBlocked(s.db, r.userID, actorID) || isMuted(s.db, r.userID, actorID) {
+			continue
+		}
+		prefs, err := s.GetPreferences(r.userID)
+		if err != nil || !prefs.Enabled(r.typ) {
+			continue
+		}
+
+		notified[r.userID] = true
+		notifications = append(notifications, model.Notification{
+			UserID:    r.userID,
+			ActorID:   actorID,
+			Type:      r.typ,
+			PostID:    post.ID,
+			CommentID: commentID,
+		})
+	}
+	if len(notifications) == 0 {
+		return
+	}
+
+	if err := s.db.Create(&notifications).Error; err != nil {
+		logrus.Errorf("创建文章 %d 的通知失败: %v", post.ID, err)
+		return
+	}
+	logrus.Debugf("文章 %d 创建通知 %d 条", post.ID, len(notifications))
+
+	// 推送给在线的接收者
+	var actor model.User
+	s.db.Select("username").First(&actor, actorID)
+	for _, n := range notifications {
+		s.hub.Publish(UserTopic(n.UserID), EventNotification, NotificationEvent{
+			ID:        n.ID,
+			Type:      n.Type,
+			ActorID:   n.ActorID,
+			Actor:     actor.Username,
+			PostID:    n.PostID,
+			CommentID: n.CommentID,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+}
+
+// visibleNotifications 排除文章已删除，或评论已删除、待审核、被判定为垃圾的通知
+func visibleNotifications(db *gorm.DB) *gorm.DB {
+	sub := db.Session(&gorm.Session{NewDB: true})
+	return db.
+		Where("post_id IN (?)", sub.Model(&model.Post{}).Select("id")).
+		Where("comment_id IS NULL OR comment_id IN (?)",
+			sub.Model(&model.Comment{}).Select("id").Where("status = ?", model.CommentStatusApproved))
+}
+
+// mentionedUsers 解析内容中提及的用户名并返回存在的用户ID
+func (s *notificationService) mentionedUsers(content string) []uint {
+	usernames := utils.ParseMentions(content)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	var ids []uint
+	if err := s.db.Model(&model.User{}).Where("username IN ? AND status = ?", usernames, model.UserStatusActive).
+		Pluck("id", &ids).Error; err != nil {
+		logrus.Errorf("查询提及的用户失败: %v", err)
+		return nil
+	}
+	return ids
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/utils"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// OAuthStateTTL 授权请求的有效期
+const OAuthStateTTL = 10 * time.Minute
+
+// OAuthService 第三方登录服务接口
+type OAuthService interface {
+	AuthCodeURL(ctx context.Context, provider string) (authURL, binding string, err error)
+	HandleCallback(ctx context.Context, provider, state, code, binding string) (*model.User, error)
+}
+
+// oauthPending 尚未完成的授权请求
+type oauthPending struct {
+	verifier  string
+	nonce     string
+	binding   string // 发起登录的浏览器持有的随机值，回调时必须一致
+	expiresAt time.Time
+}
+
+// oidcClaims ID令牌中使用到的声明
+type oidcClaims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+}
+
+// oauthService 第三方登录服务实现，基于OIDC发现协议，兼容任意标准身份提供方
+type oauthService struct {
+	db  *gorm.DB
+	cfg *config.Config
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	pending  map[string]oauthPending
+}
+
+// NewOAuthService 创建第三方登录服务实例
+func NewOAuthService(db *gorm.DB, cfg *config.Config) OAuthService {
+	return &oauthService{
+		db:      db,
+		cfg:     cfg,
+		pending: make(map[string]oauthPending),
+	}
+}
+
+// AuthCodeURL 生成授权地址（授权码模式 + PKCE），binding 需保存在发起登录的浏览器中，回调时原样传回，
+// 防止他人将自己的授权回调交给受害者完成登录
+func (s *oauthService) AuthCodeURL(ctx context.Context, provider string) (string, string, error) {
+	oauthConfig, _, err := s.config(ctx, provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return "", "", err
+	}
+	binding, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	s.mu.Lock()
+	now := time.Now()
+	for key, p := range s.pending {
+		if now.After(p.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	s.pending[state] = oauthPending{
+		verifier:  verifier,
+		nonce:     nonce,
+		binding:   binding,
+		expiresAt: now.Add(OAuthStateTTL),
+	}
+	s.mu.Unlock()
+
+	return oauthConfig.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oidc.Nonce(nonce)), binding, nil
+}
+
+// HandleCallback 处理授权回调，返回对应的本地用户（首次登录时自动创建）。
+// binding 必须与发起登录时返回的值一致
+func (s *oauthService) HandleCallback(ctx context.Context, provider, state, code, binding string) (*model.User, error) {
+	oauthConfig, oidcProvider, err := s.config(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// 取出并作废授权请求，state 只能使用一次
+	s.mu.Lock()
+	pending, ok := s.pending[state]
+	delete(s.pending, state)
+	s.mu.Unlock()
+	if !ok || time.Now().After(pending.expiresAt) {
+		logrus.Warnf("第三方登录回调的state无效: %s", state)
+		return nil, errors.New("无效或已过期的登录请求")
+	}
+	if subtle.ConstantTimeCompare([]byte(binding), []byte(pending.binding)) != 1 {
+		logrus.Warnf("第三方登录回调与发起登录的浏览器不一致: %s", state)
+		return nil, errors.New("无效或已过期的登录请求")
+	}
+
+	// 使用授权码和PKCE校验码换取令牌
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(pending.verifier))
+	if err != nil {
+		logrus.Warnf("第三方登录换取令牌失败: %v", err)
+		return nil, errors.New("第三方登录失败")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		logrus.Warn("第三方登录响应中缺少id_token")
+		return nil, errors.New("第三方登录失败")
+	}
+
+	idToken, err := oidcProvider.Verifier(&oidc.Config{ClientID: s.cfg.OIDCClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		logrus.Warnf("校验id_token失败: %v", err)
+		return nil, errors.New("第三方登录失败")
+	}
+	if idToken.Nonce != pending.nonce {
+		logrus.Warn("id_token中的nonce不匹配")
+		return nil, errors.New("第三方登录失败")
+	}
+
+	var claims oidcClaims
+	if err := idToken.Claims(&claims); err != nil {
+		logrus.Warnf("解析id_token声明失败: %v", err)
+		return nil, errors.New("第三方登录失败")
+	}
+
+	return s.findOrProvisionUser(provider, &claims)
+}
+
+// config 获取身份提供方及OAuth2配置，首次使用时进行OIDC发现
+func (s *oauthService) config(ctx context.Context, provider string) (*oauth2.Config, *oidc.Provider, error) {
+	if s.cfg.OIDCIssuer == "" || provider != s.cfg.OIDCProviderName {
+		return nil, nil, errors.New("不支持的登录方式")
+	}
+
+	s.mu.Lock()
+	oidcProvider := s.provider
+	s.mu.Unlock()
+
+	if oidcProvider == nil {
+		p, err := oidc.NewProvider(ctx, s.cfg.OIDCIssuer)
+		if err != nil {
+			logrus.Errorf("OIDC发现失败 %s: %v", s.cfg.OIDCIssuer, err)
+			return nil, nil, errors.New("身份提供方不可用")
+		}
+		s.mu.Lock()
+		s.provider = p
+		s.mu.Unlock()
+		oidcProvider = p
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(s.cfg.OIDCScopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &oauth2.Config{
+		ClientID:     s.cfg.OIDCClientID,
+		ClientSecret: s.cfg.OIDCClientSecret,
+		RedirectURL:  s.cfg.OIDCRedirectURL,
+		Endpoint:     oidcProvider.Endpoint(),
+		Scopes:       scopes,
+	}, oidcProvider, nil
+}
+
+// findOrProvisionUser 根据外部身份查找本地用户，不存在时关联或创建用户
+func (s *oauthService) findOrProvisionUser(provider string, claims *oidcClaims) (*model.User, error) {
+	var user model.User
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// 已关联的外部身份
+		var identity model.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+		if err == nil {
+			if err := tx.First(&user, identity.UserID).Error; err != nil {
+				return err
+			}
+			if claims.Email != "" && claims.Email != identity.Email {
+				return tx.Model(&identity).Update("email", claims.Email).Error
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if claims.Email == "" {
+			return errors.New("身份提供方未返回邮箱")
+		}
+
+		// 邮箱已验证时关联到同邮箱的本地用户，否则创建新用户
+		err = tx.Where("email = ?", claims.Email).First(&user).Error
+		if err == nil && !claims.EmailVerified {
+			logrus.Warnf("外部身份 %s/%s 的邮箱未验证，拒绝关联用户 %d", provider, claims.Subject, user.ID)
+			return errors.New("邮箱已存在")
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 邀请注册模式下不自动创建用户
+			if s.cfg.RegistrationMode == config.RegistrationInvite {
+				return errors.New("当前仅限邀请注册")
+			}
+			username, err := uniqueUsername(tx, claims)
+			if err != nil {
+				return err
+			}
+			// 外部身份用户没有本地密码，使用随机密码占位
+			password, err := utils.GenerateRandomString(32)
+			if err != nil {
+				return err
+			}
+			user = model.User{
+				Username: username,
+				Email:    claims.Email,
+				Role:     model.RoleUser,
+				Status:   model.UserStatusActive,
+			}
+			if err := user.SetPassword(password); err != nil {
+				return err
+			}
+			if s.cfg.RegistrationMode == config.RegistrationApproval {
+				user.Status = model.UserStatusPending
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			logrus.Infof("通过外部身份 %s 自动创建用户: %s", provider, username)
+		} else if err != nil {
+			return err
+		}
+
+		return tx.Create(&model.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}).Error
+	})
+	if err != nil {
+		logrus.Errorf("第三方登录关联用户失败 %s/%s: %v", provider, claims.Subject, err)
+		switch err.Error() {
+		case "身份提供方未返回邮箱", "邮箱已存在", "当前仅限邀请注册":
+			return nil, err
+		}
+		return nil, errors.New("第三方登录失败")
+	}
+
+	// 检查账号状态
+	if err := checkUserStatus(&user); err != nil {
+		logrus.Warnf("用户 %d 通过外部身份登录被拒绝: %v", user.ID, err)
+		return nil, err
+	}
+
+	logrus.Infof("用户 %d 通过外部身份 %s 登录成功", user.ID, provider)
+	return &user, nil
+}
+
+// uniqueUsername 根据外部身份信息生成未被占用的用户名
+func uniqueUsername(tx *gorm.DB, claims *oidcClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = claims.Name
+	}
+	if base == "" {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	// 只保留字母、数字、下划线和连字符
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			return r
+		}
+		return -1
+	}, base)
+	if runes := []rune(base); len(runes) > 40 {
+		base = string(runes[:40])
+	}
+	if len([]rune(base)) < 3 {
+		base = "user_" + base
+	}
+
+	candidate := base
+	for i := 2; i <= 20; i++ {
+		var count int64
+		if err := tx.Unscoped().Model(&model.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s_%d", base, i)
+	}
+
+	suffix, err := utils.GenerateRandomString(4)
+	if err != nil {
+		return "", err
+	}
+	return base + "_" + suffix, nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockOIDC 模拟的OIDC身份提供方，提供发现、JWKS和令牌端点
+type mockOIDC struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu         sync.Mutex
+	challenges map[string]string                 // 授权码对应的PKCE challenge
+	claims     map[string]map[string]interface{} // 授权码对应的id_token声明
+}
+
+// newMockOIDC 启动模拟的身份提供方
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成签名密钥失败: %v", err)
+	}
+	m := &mockOIDC{
+		t:          t,
+		key:        key,
+		challenges: make(map[string]string),
+		claims:     make(map[string]map[string]interface{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                m.server.URL,
+			"authorization_endpoint":                m.server.URL + "/authorize",
+			"token_endpoint":                        m.server.URL + "/token",
+			"jwks_uri":                              m.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		code := r.PostForm.Get("code")
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+		m.mu.Lock()
+		challenge, claims := m.challenges[code], m.claims[code]
+		delete(m.challenges, code)
+		delete(m.claims, code)
+		m.mu.Unlock()
+
+		if claims == nil || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     m.sign(claims),
+		})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize 模拟用户在身份提供方完成授权，返回回调中的 state 和授权码。
+// nonce 为空时使用授权地址中的 nonce
+func (m *mockOIDC) authorize(authURL, code, nonce string, claims map[string]interface{}) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatalf("解析授权地址失败: %v", err)
+	}
+	query := u.Query()
+	if nonce == "" {
+		nonce = query.Get("nonce")
+	}
+
+	full := map[string]interface{}{
+		"iss":   m.server.URL,
+		"aud":   "blog",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range claims {
+		full[k] = v
+	}
+
+	m.mu.Lock()
+	m.challenges[code] = query.Get("code_challenge")
+	m.claims[code] = full
+	m.mu.Unlock()
+	return query.Get("state")
+}
+
+// sign 使用 RS256 签发 id_token
+func (m *mockOIDC) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sum := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, m.key, crypto.SHA256, sum[:])
+	if err != nil {
+		m.t.Fatalf("签发id_token失败: %v", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// TestOAuthCallback 使用模拟的身份提供方验证授权回调的各项校验
+func TestOAuthCallback(t *testing.T) {
+	idp := newMockOIDC(t)
+	db := newTestDB(t)
+	cfg := &config.Config{
+		OIDCProviderName: "mock",
+		OIDCIssuer:       idp.server.URL,
+		OIDCClientID:     "blog",
+		OIDCClientSecret: "secret",
+		OIDCRedirectURL:  "http://localhost/api/oauth/mock/callback",
+		OIDCScopes:       "openid,email,profile",
+		RegistrationMode: config.RegistrationOpen,
+	}
+	oauth := NewOAuthService(db, cfg)
+	ctx := context.Background()
+
+	local := model.User{Username: "alice", Email: "alice@example.com", Role: model.RoleUser, Status: model.UserStatusActive}
+	if err := local.SetPassword("s3cret-pass"); err != nil {
+		t.Fatalf("设置密码失败: %v", err)
+	}
+	if err := db.Create(&local).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	// begin 发起登录并在身份提供方完成授权
+	begin := func(code, nonce string, claims map[string]interface{}) (state, binding string) {
+		t.Helper()
+		authURL, binding, err := oauth.AuthCodeURL(ctx, "mock")
+		if err != nil {
+			t.Fatalf("生成授权地址失败: %v", err)
+		}
+		if !strings.HasPrefix(authURL, idp.server.URL+"/authorize") {
+			t.Fatalf("授权地址 = %s", authURL)
+		}
+		return idp.authorize(authURL, code, nonce, claims), binding
+	}
+
+	t.Run("首次登录创建用户且state不能重复使用", func(t *testing.T) {
+		state, binding := begin("code-new", "", map[string]interface{}{
+			"sub": "new-user", "email": "bob@example.com", "email_verified": true, "preferred_username": "bob",
+		})
+		user, err := oauth.HandleCallback(ctx, "mock", state, "code-new", binding)
+		if err != nil {
+			t.Fatalf("登录失败: %v", err)
+		}
+		if user.Username != "bob" || user.Email != "bob@example.com" {
+			t.Errorf("创建的用户 = %s <%s>", user.Username, user.Email)
+		}
+
+		if _, err := oauth.HandleCallback(ctx, "mock", state, "code-new", binding); err == nil || err.Error() != "无效或已过期的登录请求" {
+			t.Errorf("重复使用 state 的错误 = %v", err)
+		}
+	})
+
+	t.Run("回调与发起登录的浏览器不一致", func(t *testing.T) {
+		state, _ := begin("code-binding", "", map[string]interface{}{
+			"sub": "attacker", "email": "mallory@example.com", "email_verified": true,
+		})
+		if _, err := oauth.HandleCallback(ctx, "mock", state, "code-binding", "other-browser"); err == nil || err.Error() != "无效或已过期的登录请求" {
+			t.Errorf("绑定值不一致的错误 = %v", err)
+		}
+	})
+
+	t.Run("nonce不匹配", func(t *testing.T) {
+		state, binding := begin("code-nonce", "forged", map[string]interface{}{
+			"sub": "nonce-user", "email": "carol@example.com", "email_verified": true,
+		})
+		if _, err := oauth.HandleCallback(ctx, "mock", state, "code-nonce", binding); err == nil || err.Error() != "第三方登录失败" {
+			t.Errorf("nonce 不匹配的错误 = %v", err)
+		}
+	})
+
+	t.Run("未验证的邮箱不能关联已有用户", func(t *testing.T) {
+		state, binding := begin("code-unverified", "", map[string]interface{}{
+			"sub": "unverified", "email": local.Email, "email_verified": false,
+		})
+		if _, err := oauth.HandleCallback(ctx, "mock", state, "code-unverified", binding); err == nil || err.Error() != "邮箱已存在" {
+			t.Errorf("未验证邮箱的错误 = %v", err)
+		}
+		var count int64
+		db.Model(&model.UserIdentity{}).Where("user_id = ?", local.ID).Count(&count)
+		if count != 0 {
+			t.Errorf("未验证邮箱关联的外部身份数 = %d, 期望 0", count)
+		}
+	})
+
+	t.Run("已验证的邮箱关联已有用户", func(t *testing.T) {
+		state, binding := begin("code-verified", "", map[string]interface{}{
+			"sub": "verified", "email": local.Email, "email_verified": true,
+		})
+		user, err := oauth.HandleCallback(ctx, "mock", state, "code-verified", binding)
+		if err != nil {
+			t.Fatalf("登录失败: %v", err)
+		}
+		if user.ID != local.ID {
+			t.Errorf("登录的用户 = %d, 期望 %d", user.ID, local.ID)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"blog-backend/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CursorPage 游标分页结果
+type CursorPage struct {
+	NextCursor string // 下一页（更早的数据），为空表示没有更多
+	PrevCursor string // 上一页（更新的数据），为空表示已是第一页
+	Total      *int64 // 仅在请求总数时返回
+}
+
+// paginateByCursor 按 (created_at, id) 倒序进行游标分页。
+// query 需已包含过滤条件；withTotal 为 true 时额外执行一次 COUNT
+func paginateByCursor[T any](query *gorm.DB, cursor string, pageSize int, withTotal bool, key func(*T) (time.Time, uint)) ([]T, *CursorPage, error) {
+	page := &CursorPage{}
+
+	if withTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return nil, nil, err
+		}
+		page.Total = &total
+	}
+
+	var c *utils.Cursor
+	if cursor != "" {
+		var err error
+		if c, err = utils.DecodeCursor(cursor); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	q := query.Session(&gorm.Session{})
+	switch {
+	case c == nil:
+		q = q.Order("created_at DESC, id DESC")
+	case c.Before:
+		q = q.Where("created_at > ? OR (created_at = ? AND id > ?)", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at ASC, id ASC")
+	default:
+		q = q.Where("created_at < ? OR (created_at = ? AND id < ?)", c.CreatedAt, c.CreatedAt, c.ID).
+			Order("created_at DESC, id DESC")
+	}
+
+	// 多取一条用于判断是否还有更多数据
+	var items []T
+	if err := q.Limit(pageSize + 1).Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+
+	// 向前翻页时结果为正序，需要反转回倒序
+	if c != nil && c.Before {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	if len(items) > 0 {
+		firstAt, firstID := key(&items[0])
+		lastAt, lastID := key(&items[len(items)-1])
+		if (c == nil || !c.Before) && hasMore || c != nil && c.Before {
+			page.NextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: lastAt, ID: lastID})
+		}
+		if c != nil && !c.Before || c != nil && c.Before && hasMore {
+			page.PrevCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: firstAt, ID: firstID, Before: true})
+		}
+	}
+
+	return items, page, nil
+}
@@ -1,67 +1,108 @@
 package service
 
 import (
+	"blog-backend/config"
 	"blog-backend/model"
+	"blog-backend/utils"
 	"errors"
+	"fmt"
+	"time"
 
-	"gorm.io/gorm"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // PostService 文章服务接口
 type PostService interface {
-	CreatePost(title, content string, userID uint) (*model.Post, error)
-	GetPostByID(id uint) (*model.Post, error)
-	ListPosts(page, pageSize int) ([]model.Post, int64, error)
-	UpdatePost(id uint, title, content string, userID uint) (*model.Post, error)
+	CreatePost(title, content string, userID uint, visibility, password string) (*model.Post, error)
+	GetPostByID(id, viewerID uint) (*model.Post, error)
+	ListPosts(viewerID uint, page, pageSize int) ([]model.Post, int64, error)
+	ListPostsByCursor(viewerID uint, cursor string, pageSize int, withTotal bool) ([]model.Post, *CursorPage, error)
+	UpdatePost(id uint, title, content string, userID uint, visibility, password string, version uint) (*model.Post, error)
+	PatchPost(id, userID uint, patch PostPatch, version uint) (*model.Post, error)
 	DeletePost(id uint, userID uint) error
-	GetUserPosts(userID uint, page, pageSize int) ([]model.Post, int64, error)
+	GetUserPosts(userID, viewerID uint, page, pageSize int) ([]model.Post, int64, error)
+	GetUserPostsByCursor(userID, viewerID uint, cursor string, pageSize int, withTotal bool) ([]model.Post, *CursorPage, error)
+	GetPostBySlug(slug string, viewerID uint) (*model.Post, error)
+	CheckPostAccess(post *model.Post, viewerID uint, unlockToken string) error
+	CheckPostAccessByID(id, viewerID uint, unlockToken string) error
+	UpdateCommentSettings(id, userID uint, mode string, autoCloseDays *int) (*model.Post, error)
+	CommentState(post *model.Post) CommentState
+	UnlockPost(id uint, password string) (string, error)
+	BackfillSlugs() error
+	BackfillContentHTML() error
 }
 
 // postService 文章服务实现
 type postService struct {
-	db *gorm.DB
+	db                  *gorm.DB
+	cfg                 *config.Config
+	notificationService NotificationService
 }
 
 // NewPostService 创建文章服务实例
-func NewPostService(db *gorm.DB) PostService {
-	return &postService{db: db}
+func NewPostService(db *gorm.DB, cfg *config.Config, notificationService NotificationService) PostService {
+	return &postService{db: db, cfg: cfg, notificationService: notificationService}
 }
 
-// CreatePost 创建文章
-func (s *postService) CreatePost(title, content string, userID uint) (*model.Post, error) {
+// CreatePost 创建文章，并通知文章中提及的用户
+func (s *postService) CreatePost(title, content string, userID uint, visibility, password string) (*model.Post, error) {
 	post := &model.Post{
-		Title:   title,
-		Content: content,
-		UserID:  userID,
+		Title:       title,
+		Content:     content,
+		ContentHTML: utils.RenderPostMarkdown(content),
+		UserID:      userID,
+		Version:     1,
+		CommentMode: s.cfg.CommentDefaultMode,
+	}
+	if err := applyVisibility(post, visibility, password); err != nil {
+		return nil, err
 	}
 
-	if err := s.db.Create(post).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if err := assignSlug(tx, post); err != nil {
+			return err
+		}
+		return syncPostMedia(tx, post)
+	})
+	if err != nil {
 		logrus.Errorf("创建文章失败: %v", err)
 		return nil, err
 	}
 
 	logrus.Infof("用户 %d 创建文章成功: %s", userID, title)
+	s.notificationService.NotifyPostMentions(post, "")
 	return post, nil
 }
 
-// GetPostByID 根据ID获取文章
-func (s *postService) GetPostByID(id uint) (*model.Post, error) {
+// GetPostByID 根据ID获取文章，只加载已通过审核且不是访问者屏蔽或隐藏的用户发布的评论，
+// 禁用评论的文章不加载评论
+func (s *postService) GetPostByID(id, viewerID uint) (*model.Post, error) {
 	var post model.Post
-	if err := s.db.Preload("User").Preload("Comments").Preload("Comments.User").First(&post, id).Error; err != nil {
+	if err := s.db.Preload("User").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return db.Scopes(hiddenAuthors(viewerID, "user_id")).Where("status = ?", model.CommentStatusApproved)
+		}).
+		Preload("Comments.User").First(&post, id).Error; err != nil {
 		logrus.Errorf("获取文章 %d 失败: %v", id, err)
 		return nil, err
 	}
+	if post.CommentMode == config.CommentsDisabled {
+		post.Comments = nil
+	}
 	return &post, nil
 }
 
-// ListPosts 获取文章列表（分页）
-func (s *postService) ListPosts(page, pageSize int) ([]model.Post, int64, error) {
+// ListPosts 获取文章列表（分页），不包含访问者屏蔽或隐藏的作者的文章
+func (s *postService) ListPosts(viewerID uint, page, pageSize int) ([]model.Post, int64, error) {
 	var posts []model.Post
 	var total int64
 
 	// 计算总记录数
-	if err := s.db.Model(&model.Post{}).Count(&total).Error; err != nil {
+	if err := s.db.Model(&model.Post{}).Scopes(listedPosts, hiddenAuthors(viewerID, "user_id")).Count(&total).Error; err != nil {
 		logrus.Errorf("计算文章总数失败: %v", err)
 		return nil, 0, err
 	}
@@ -70,7 +111,7 @@ func (s *postService) ListPosts(page, pageSize int) ([]model.Post, int64, error)
 	offset := (page - 1) * pageSize
 
 	// 获取分页数据
-	if err := s.db.Preload("User").Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&posts).Error; err != nil {
+	if err := s.db.Scopes(listedPosts, hiddenAuthors(viewerID, "user_id")).Preload("User").Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&posts).Error; err != nil {
 		logrus.Errorf("获取文章列表失败: %v", err)
 		return nil, 0, err
 	}
@@ -78,8 +119,44 @@ func (s *postService) ListPosts(page, pageSize int) ([]model.Post, int64, error)
 	return posts, total, nil
 }
 
-// UpdatePost 更新文章
-func (s *postService) UpdatePost(id uint, title, content string, userID uint) (*model.Post, error) {
+// ListPostsByCursor 获取文章列表（游标分页），不包含访问者屏蔽或隐藏的作者的文章
+func (s *postService) ListPostsByCursor(viewerID uint, cursor string, pageSize int, withTotal bool) ([]model.Post, *CursorPage, error) {
+	query := s.db.Model(&model.Post{}).Scopes(listedPosts, hiddenAuthors(viewerID, "user_id")).Preload("User")
+	posts, page, err := paginateByCursor(query, cursor, pageSize, withTotal, postCursorKey)
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取文章列表失败: %v", err)
+	}
+	return posts, page, err
+}
+
+// postCursorKey 文章的游标排序键
+func postCursorKey(p *model.Post) (time.Time, uint) {
+	return p.CreatedAt, p.ID
+}
+
+// PostPatch 文章部分更新的内容，字段为 nil 表示不修改；Password 指向空字符串表示移除密码
+type PostPatch struct {
+	Title      *string
+	Content    *string
+	Visibility *string
+	Password   *string
+}
+
+// UpdatePost 更新文章。version 为客户端持有的版本号，与当前版本不一致时返回
+// "文章已被修改" 错误及当前文章；version 为 0 时不检查版本
+func (s *postService) UpdatePost(id uint, title, content string, userID uint, visibility, password string, version uint) (*model.Post, error) {
+	patch := PostPatch{Title: &title, Content: &content}
+	if visibility != "" {
+		patch.Visibility = &visibility
+	}
+	if password != "" {
+		patch.Password = &password
+	}
+	return s.PatchPost(id, userID, patch, version)
+}
+
+// PatchPost 部分更新文章，只写入提供的字段，版本检查规则与 UpdatePost 相同；内容中新提及的用户会收到通知
+func (s *postService) PatchPost(id, userID uint, patch PostPatch, version uint) (*model.Post, error) {
 	// 检查文章是否存在
 	var post model.Post
 	if err := s.db.First(&post, id).Error; err != nil {
@@ -93,15 +170,91 @@ func (s *postService) UpdatePost(id uint, title, content string, userID uint) (*
 		return nil, errors.New("没有权限更新此文章")
 	}
 
-	// 更新文章
-	post.Title = title
-	post.Content = content
-	if err := s.db.Save(&post).Error; err != nil {
+	// 检查版本
+	if version == 0 {
+		version = post.Version
+	}
+	if post.Version != version {
+		logrus.Warnf("用户 %d 更新文章 %d 时版本冲突: 提交 %d，当前 %d", userID, id, version, post.Version)
+		return &post, errors.New("文章已被修改")
+	}
+
+	// 收集需要更新的字段，标题变化导致 slug 变化时生成新 slug，旧 slug 保留用于跳转
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+	titleChanged := false
+	previousContent := post.Content
+	if patch.Title != nil {
+		titleChanged = utils.Slugify(*patch.Title) != utils.Slugify(post.Title)
+		post.Title = *patch.Title
+		updates["title"] = post.Title
+	}
+	if patch.Content != nil {
+		post.Content = *patch.Content
+		post.ContentHTML = utils.RenderPostMarkdown(post.Content)
+		updates["content"] = post.Content
+		updates["content_html"] = post.ContentHTML
+	}
+	if patch.Visibility != nil || patch.Password != nil {
+		visibility, password := post.Visibility, ""
+		if patch.Visibility != nil {
+			visibility = *patch.Visibility
+		}
+		if patch.Password != nil {
+			password = *patch.Password
+			if password == "" {
+				post.Password = ""
+			}
+		}
+		if err := applyVisibility(&post, visibility, password); err != nil {
+			return nil, err
+		}
+		updates["visibility"] = post.Visibility
+		updates["password"] = post.Password
+	}
+
+	// 没有需要更新的字段
+	if len(updates) == 1 {
+		return &post, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if titleChanged || post.Slug == "" {
+			if err := assignSlug(tx, &post); err != nil {
+				return err
+			}
+			updates["slug"] = post.Slug
+		}
+		// 仅在版本未变化时更新，避免并发编辑互相覆盖
+		result := tx.Model(&post).Where("version = ?", version).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("文章已被修改")
+		}
+		if patch.Content != nil {
+			return syncPostMedia(tx, &post)
+		}
+		return nil
+	})
+	if err != nil {
+		if err.Error() == "文章已被修改" {
+			// 返回最新的文章，便于客户端获取当前版本
+			var current model.Post
+			if s.db.First(&current, id).Error == nil {
+				return &current, err
+			}
+			return &post, err
+		}
 		logrus.Errorf("更新文章 %d 失败: %v", id, err)
 		return nil, err
 	}
+	post.Version = version + 1
 
 	logrus.Infof("用户 %d 更新文章成功: %d", userID, id)
+	if patch.Content != nil {
+		s.notificationService.NotifyPostMentions(&post, previousContent)
+	}
 	return &post, nil
 }
 
@@ -120,8 +273,15 @@ func (s *postService) DeletePost(id uint, userID uint) error {
 		return errors.New("没有权限删除此文章")
 	}
 
-	// 删除文章（软删除）
-	if err := s.db.Delete(&post).Error; err != nil {
+	// 删除文章及其评论（软删除），使用相同的删除时间，恢复文章时据此一并恢复评论
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Comment{}).Where("post_id = ?", id).UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&post).UpdateColumn("deleted_at", now).Error
+	})
+	if err != nil {
 		logrus.Errorf("删除文章 %d 失败: %v", id, err)
 		return err
 	}
@@ -131,12 +291,18 @@ func (s *postService) DeletePost(id uint, userID uint) error {
 }
 
 // GetUserPosts 获取用户的文章列表
-func (s *postService) GetUserPosts(userID uint, page, pageSize int) ([]model.Post, int64, error) {
+func (s *postService) GetUserPosts(userID, viewerID uint, page, pageSize int) ([]model.Post, int64, error) {
 	var posts []model.Post
 	var total int64
 
+	// 作者本人和管理员可以看到全部文章
+	query := s.db.Model(&model.Post{}).Where("user_id = ?", userID)
+	if viewerID != userID && !isAdminUser(s.db, viewerID) {
+		query = query.Scopes(listedPosts)
+	}
+
 	// 计算总记录数
-	if err := s.db.Model(&model.Post{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		logrus.Errorf("计算用户 %d 的文章总数失败: %v", userID, err)
 		return nil, 0, err
 	}
@@ -145,10 +311,240 @@ func (s *postService) GetUserPosts(userID uint, page, pageSize int) ([]model.Pos
 	offset := (page - 1) * pageSize
 
 	// 获取分页数据
-	if err := s.db.Where("user_id = ?", userID).Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&posts).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&posts).Error; err != nil {
 		logrus.Errorf("获取用户 %d 的文章列表失败: %v", userID, err)
 		return nil, 0, err
 	}
 
 	return posts, total, nil
 }
+
+// GetUserPostsByCursor 获取用户的文章列表（游标分页）
+func (s *postService) GetUserPostsByCursor(userID, viewerID uint, cursor string, pageSize int, withTotal bool) ([]model.Post, *CursorPage, error) {
+	query := s.db.Model(&model.Post{}).Where("user_id = ?", userID)
+	if viewerID != userID && !isAdminUser(s.db, viewerID) {
+		query = query.Scopes(listedPosts)
+	}
+	posts, page, err := paginateByCursor(query, cursor, pageSize, withTotal, postCursorKey)
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的文章列表失败: %v", userID, err)
+	}
+	return posts, page, err
+}
+
+// GetPostBySlug 根据 slug 获取文章，历史 slug 同样可以找到文章
+func (s *postService) GetPostBySlug(slug string, viewerID uint) (*model.Post, error) {
+	var postSlug model.PostSlug
+	if err := s.db.Where("slug = ?", slug).First(&postSlug).Error; err != nil {
+		logrus.Warnf("slug %s 对应的文章不存在: %v", slug, err)
+		return nil, errors.New("文章不存在")
+	}
+	return s.GetPostByID(postSlug.PostID, viewerID)
+}
+
+// CheckPostAccess 检查访问者能否查看文章内容。
+// 私密文章对无权访问者表现为不存在；密码保护文章需要有效的访问令牌
+func (s *postService) CheckPostAccess(post *model.Post, viewerID uint, unlockToken string) error {
+	if viewerID != 0 && (post.UserID == viewerID || isAdminUser(s.db, viewerID)) {
+		return nil
+	}
+
+	switch post.Visibility {
+	case model.VisibilityPrivate:
+		return errors.New("文章不存在")
+	case model.VisibilityPassword:
+		if !utils.ValidateUnlockToken(unlockToken, post.ID, post.Password, s.cfg) {
+			return errors.New("文章需要密码")
+		}
+	}
+	return nil
+}
+
+// CheckPostAccessByID 根据文章ID检查访问权限
+func (s *postService) CheckPostAccessByID(id, viewerID uint, unlockToken string) error {
+	var post model.Post
+	if err := s.db.First(&post, id).Error; err != nil {
+		return errors.New("文章不存在")
+	}
+	return s.CheckPostAccess(&post, viewerID, unlockToken)
+}
+
+// UpdateCommentSettings 更新文章的评论设置，仅作者本人可以修改；autoCloseDays 为空时使用站点默认值
+func (s *postService) UpdateCommentSettings(id, userID uint, mode string, autoCloseDays *int) (*model.Post, error) {
+	switch mode {
+	case config.CommentsOpen, config.CommentsDisabled, config.CommentsLocked:
+	default:
+		return nil, errors.New("无效的评论模式")
+	}
+
+	// 检查文章是否存在
+	var post model.Post
+	if err := s.db.First(&post, id).Error; err != nil {
+		logrus.Errorf("更新文章 %d 的评论设置失败: 文章不存在 - %v", id, err)
+		return nil, errors.New("文章不存在")
+	}
+
+	// 检查权限
+	if post.UserID != userID {
+		logrus.Warnf("用户 %d 尝试修改不属于自己的文章 %d 的评论设置", userID, id)
+		return nil, errors.New("没有权限更新此文章")
+	}
+
+	post.CommentMode = mode
+	post.CommentAutoCloseDays = autoCloseDays
+	if err := s.db.Model(&post).Select("comment_mode", "comment_auto_close_days").Updates(&post).Error; err != nil {
+		logrus.Errorf("更新文章 %d 的评论设置失败: %v", id, err)
+		return nil, err
+	}
+
+	logrus.Infof("用户 %d 更新文章 %d 的评论设置: %s", userID, id, mode)
+	return &post, nil
+}
+
+// CommentState 获取文章评论的当前状态
+func (s *postService) CommentState(post *model.Post) CommentState {
+	return commentState(post, s.cfg.CommentAutoCloseDays, time.Now())
+}
+
+// UnlockPost 校验密码保护文章的密码，成功后返回访问令牌
+func (s *postService) UnlockPost(id uint, password string) (string, error) {
+	var post model.Post
+	if err := s.db.First(&post, id).Error; err != nil {
+		return "", errors.New("文章不存在")
+	}
+	if post.Visibility == model.VisibilityPrivate {
+		return "", errors.New("文章不存在")
+	}
+	if post.Visibility != model.VisibilityPassword {
+		return "", errors.New("文章未设置访问密码")
+	}
+
+	if ok, _ := utils.VerifyPassword(post.Password, password); !ok {
+		logrus.Warnf("文章 %d 访问密码错误", id)
+		return "", errors.New("访问密码错误")
+	}
+
+	return utils.GenerateUnlockToken(post.ID, post.Password, s.cfg)
+}
+
+// BackfillSlugs 为缺少 slug 的历史文章生成 slug
+func (s *postService) BackfillSlugs() error {
+	var posts []model.Post
+	if err := s.db.Where("slug = '' OR slug IS NULL").Find(&posts).Error; err != nil {
+		logrus.Errorf("查询缺少slug的文章失败: %v", err)
+		return err
+	}
+
+	for i := range posts {
+		post := &posts[i]
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			return assignSlug(tx, post)
+		})
+		if err != nil {
+			logrus.Errorf("为文章 %d 生成slug失败: %v", post.ID, err)
+			return err
+		}
+	}
+
+	if len(posts) > 0 {
+		logrus.Infof("已为 %d 篇文章生成slug", len(posts))
+	}
+	return nil
+}
+
+// BackfillContentHTML 为缺少HTML缓存的历史文章渲染内容
+func (s *postService) BackfillContentHTML() error {
+	var posts []model.Post
+	if err := s.db.Where("content_html = '' OR content_html IS NULL").Find(&posts).Error; err != nil {
+		logrus.Errorf("查询缺少HTML缓存的文章失败: %v", err)
+		return err
+	}
+
+	for _, post := range posts {
+		if err := s.db.Model(&post).UpdateColumn("content_html", utils.RenderPostMarkdown(post.Content)).Error; err != nil {
+			logrus.Errorf("渲染文章 %d 的HTML失败: %v", post.ID, err)
+			return err
+		}
+	}
+
+	if len(posts) > 0 {
+		logrus.Infof("已为 %d 篇文章渲染HTML", len(posts))
+	}
+	return nil
+}
+
+// applyVisibility 设置文章可见性，visibility 为空时保持原设置；
+// 密码保护文章未提供新密码时沿用原密码
+func applyVisibility(post *model.Post, visibility, password string) error {
+	if visibility == "" {
+		visibility = post.Visibility
+	}
+	if visibility == "" {
+		visibility = model.VisibilityPublic
+	}
+
+	switch visibility {
+	case model.VisibilityPublic, model.VisibilityUnlisted, model.VisibilityPrivate:
+		post.Password = ""
+	case model.VisibilityPassword:
+		if password != "" {
+			hash, err := utils.HashPassword(password)
+			if err != nil {
+				return err
+			}
+			post.Password = hash
+		} else if post.Password == "" {
+			return errors.New("密码保护的文章需要设置访问密码")
+		}
+	default:
+		return errors.New("无效的可见性设置")
+	}
+
+	post.Visibility = visibility
+	return nil
+}
+
+// listedPosts 出现在文章列表中的文章：公开和密码保护的文章，不公开列出和私密文章除外
+func listedPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("visibility IN ?", []string{model.VisibilityPublic, model.VisibilityPassword})
+}
+
+// isAdminUser 判断用户是否为管理员
+func isAdminUser(db *gorm.DB, userID uint) bool {
+	if userID == 0 {
+		return false
+	}
+	var count int64
+	db.Model(&model.User{}).Where("id = ? AND role = ?", userID, model.RoleAdmin).Count(&count)
+	return count > 0
+}
+
+// assignSlug 根据标题为文章分配唯一 slug，并记录到 slug 表中。
+// 冲突时依次追加 -2、-3 等后缀；文章自己用过的 slug 可以重新使用
+func assignSlug(tx *gorm.DB, post *model.Post) error {
+	base := utils.Slugify(post.Title)
+
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		var existing model.PostSlug
+		err := tx.Where("slug = ?", candidate).First(&existing).Error
+		if err == nil {
+			if existing.PostID != post.ID {
+				continue
+			}
+		} else if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Create(&model.PostSlug{PostID: post.ID, Slug: candidate}).Error; err != nil {
+				return err
+			}
+		} else {
+			return err
+		}
+
+		post.Slug = candidate
+		return tx.Model(post).UpdateColumn("slug", candidate).Error
+	}
+}
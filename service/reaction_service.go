@@ -0,0 +1,193 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostStats 文章互动统计
+type PostStats struct {
+	Reactions     map[string]int64 `json:"reactions"`
+	BookmarkCount int64            `json:"bookmark_count"`
+}
+
+// ReactionService 文章表态与收藏服务接口
+type ReactionService interface {
+	ReactionTypes() []string
+	AddReaction(postID, userID uint, reactionType string) error
+	RemoveReaction(postID, userID uint, reactionType string) error
+	AddBookmark(postID, userID uint) error
+	RemoveBookmark(postID, userID uint) error
+	ListBookmarks(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Bookmark, *CursorPage, error)
+	GetPostStats(postIDs []uint) (map[uint]*PostStats, error)
+}
+
+// reactionService 文章表态与收藏服务实现
+type reactionService struct {
+	db    *gorm.DB
+	types []string
+}
+
+// NewReactionService 创建文章表态与收藏服务实例
+func NewReactionService(db *gorm.DB, cfg *config.Config) ReactionService {
+	var types []string
+	for _, t := range strings.Split(cfg.ReactionTypes, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return &reactionService{db: db, types: types}
+}
+
+// ReactionTypes 获取支持的表态类型
+func (s *reactionService) ReactionTypes() []string {
+	return s.types
+}
+
+// AddReaction 添加表态，重复添加不报错
+func (s *reactionService) AddReaction(postID, userID uint, reactionType string) error {
+	if !s.validType(reactionType) {
+		return errors.New("不支持的表态类型")
+	}
+	if err := s.checkPost(postID); err != nil {
+		return err
+	}
+
+	reaction := model.Reaction{PostID: postID, UserID: userID, Type: reactionType}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction).Error; err != nil {
+		logrus.Errorf("用户 %d 对文章 %d 添加表态失败: %v", userID, postID, err)
+		return err
+	}
+	return nil
+}
+
+// RemoveReaction 取消表态，表态不存在时不报错
+func (s *reactionService) RemoveReaction(postID, userID uint, reactionType string) error {
+	if !s.validType(reactionType) {
+		return errors.New("不支持的表态类型")
+	}
+
+	if err := s.db.Where("post_id = ? AND user_id = ? AND type = ?", postID, userID, reactionType).
+		Delete(&model.Reaction{}).Error; err != nil {
+		logrus.Errorf("用户 %d 取消文章 %d 的表态失败: %v", userID, postID, err)
+		return err
+	}
+	return nil
+}
+
+// AddBookmark 收藏文章，重复收藏不报错
+func (s *reactionService) AddBookmark(postID, userID uint) error {
+	if err := s.checkPost(postID); err != nil {
+		return err
+	}
+
+	bookmark := model.Bookmark{PostID: postID, UserID: userID}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&bookmark).Error; err != nil {
+		logrus.Errorf("用户 %d 收藏文章 %d 失败: %v", userID, postID, err)
+		return err
+	}
+	return nil
+}
+
+// RemoveBookmark 取消收藏
+func (s *reactionService) RemoveBookmark(postID, userID uint) error {
+	if err := s.db.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&model.Bookmark{}).Error; err != nil {
+		logrus.Errorf("用户 %d 取消收藏文章 %d 失败: %v", userID, postID, err)
+		return err
+	}
+	return nil
+}
+
+// ListBookmarks 获取用户的收藏列表（游标分页，按收藏时间倒序），已删除的文章不会出现，
+// 他人的私密文章仅对管理员显示
+func (s *reactionService) ListBookmarks(userID uint, cursor string, pageSize int, withTotal bool) ([]model.Bookmark, *CursorPage, error) {
+	posts := s.db.Model(&model.Post{}).Select("id")
+	if !isAdminUser(s.db, userID) {
+		posts = posts.Where("visibility <> ? OR user_id = ?", model.VisibilityPrivate, userID)
+	}
+	query := s.db.Model(&model.Bookmark{}).Preload("Post").Preload("Post.User").
+		Where("user_id = ? AND post_id IN (?)", userID, posts)
+	bookmarks, page, err := paginateByCursor(query, cursor, pageSize, withTotal, func(b *model.Bookmark) (time.Time, uint) {
+		return b.CreatedAt, b.ID
+	})
+	if err != nil && err.Error() != "无效的分页游标" {
+		logrus.Errorf("获取用户 %d 的收藏列表失败: %v", userID, err)
+	}
+	return bookmarks, page, err
+}
+
+// GetPostStats 批量获取文章的表态数与收藏数，每类统计只执行一次分组查询，回收站中的文章统计为 0
+func (s *reactionService) GetPostStats(postIDs []uint) (map[uint]*PostStats, error) {
+	stats := make(map[uint]*PostStats, len(postIDs))
+	for _, id := range postIDs {
+		stats[id] = &PostStats{Reactions: map[string]int64{}}
+	}
+	if len(postIDs) == 0 {
+		return stats, nil
+	}
+	livePosts := s.db.Model(&model.Post{}).Select("id").Where("id IN ?", postIDs)
+
+	var reactionRows []struct {
+		PostID uint
+		Type   string
+		Count  int64
+	}
+	if err := s.db.Model(&model.Reaction{}).
+		Select("post_id, type, COUNT(*) AS count").
+		Where("post_id IN (?)", livePosts).
+		Group("post_id, type").
+		Scan(&reactionRows).Error; err != nil {
+		logrus.Errorf("统计文章表态失败: %v", err)
+		return nil, err
+	}
+	for _, row := range reactionRows {
+		stats[row.PostID].Reactions[row.Type] = row.Count
+	}
+
+	var bookmarkRows []struct {
+		PostID uint
+		Count  int64
+	}
+	if err := s.db.Model(&model.Bookmark{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN (?)", livePosts).
+		Group("post_id").
+		Scan(&bookmarkRows).Error; err != nil {
+		logrus.Errorf("统计文章收藏数失败: %v", err)
+		return nil, err
+	}
+	for _, row := range bookmarkRows {
+		stats[row.PostID].BookmarkCount = row.Count
+	}
+
+	return stats, nil
+}
+
+// validType 检查表态类型是否在配置的范围内
+func (s *reactionService) validType(reactionType string) bool {
+	for _, t := range s.types {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPost 检查文章是否存在，访问权限由调用方通过 PostService.CheckPostAccess 检查
+func (s *reactionService) checkPost(postID uint) error {
+	var count int64
+	if err := s.db.Model(&model.Post{}).Where("id = ?", postID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("文章不存在")
+	}
+	return nil
+}
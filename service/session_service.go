@@ -0,0 +1,198 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/utils"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// lastSeenInterval 最近活跃时间的最小刷新间隔，避免每个请求都写库
+const lastSeenInterval = time.Minute
+
+// SessionService 会话服务接口
+type SessionService interface {
+	CreateSession(userID uint, userAgent, ip string) (*model.Session, error)
+	ValidateSession(sessionID string, userID uint) error
+	ListSessions(userID uint) ([]model.Session, error)
+	RevokeSession(id uint, userID uint) error
+	RevokeOtherSessions(userID uint, currentSessionID string) (int64, error)
+	CreateStreamTicket(userID uint, sessionID string) (*model.StreamTicket, error)
+	RedeemStreamTicket(ticket string) (*model.StreamTicket, error)
+}
+
+// sessionService 会话服务实现
+type sessionService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewSessionService 创建会话服务实例
+func NewSessionService(db *gorm.DB, cfg *config.Config) SessionService {
+	return &sessionService{db: db, cfg: cfg}
+}
+
+// CreateSession 为一次登录创建会话
+func (s *sessionService) CreateSession(userID uint, userAgent, ip string) (*model.Session, error) {
+	expiry, err := time.ParseDuration(s.cfg.JWTExpiry)
+	if err != nil {
+		logrus.Errorf("解析JWT过期时间错误: %v", err)
+		return nil, err
+	}
+
+	sessionID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		logrus.Errorf("生成会话标识失败: %v", err)
+		return nil, err
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		SessionID:  sessionID,
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(expiry),
+	}
+
+	if err := s.db.Create(session).Error; err != nil {
+		logrus.Errorf("创建会话失败: %v", err)
+		return nil, err
+	}
+
+	logrus.Infof("用户 %d 创建会话成功: %d", userID, session.ID)
+	return session, nil
+}
+
+// ValidateSession 校验会话是否有效，并刷新最近活跃时间
+func (s *sessionService) ValidateSession(sessionID string, userID uint) error {
+	if sessionID == "" {
+		return errors.New("会话已失效")
+	}
+
+	var session model.Session
+	if err := s.db.Where("session_id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		logrus.Warnf("用户 %d 的会话不存在: %v", userID, err)
+		return errors.New("会话已失效")
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		logrus.Warnf("用户 %d 使用已注销或已过期的会话 %d", userID, session.ID)
+		return errors.New("会话已失效")
+	}
+
+	if now.Sub(session.LastSeenAt) >= lastSeenInterval {
+		if err := s.db.Model(&session).UpdateColumn("last_seen_at", now).Error; err != nil {
+			logrus.Errorf("更新会话 %d 活跃时间失败: %v", session.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// ListSessions 获取用户的有效会话列表
+func (s *sessionService) ListSessions(userID uint) ([]model.Session, error) {
+	var sessions []model.Session
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		logrus.Errorf("获取用户 %d 的会话列表失败: %v", userID, err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession 注销指定会话
+func (s *sessionService) RevokeSession(id uint, userID uint) error {
+	var session model.Session
+	if err := s.db.Where("revoked_at IS NULL").First(&session, id).Error; err != nil {
+		logrus.Errorf("注销会话 %d 失败: 会话不存在 - %v", id, err)
+		return errors.New("会话不存在")
+	}
+
+	// 检查权限
+	if session.UserID != userID {
+		logrus.Warnf("用户 %d 尝试注销不属于自己的会话 %d", userID, id)
+		return errors.New("会话不存在")
+	}
+
+	if err := s.db.Model(&session).UpdateColumn("revoked_at", time.Now()).Error; err != nil {
+		logrus.Errorf("注销会话 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 注销会话成功: %d", userID, id)
+	return nil
+}
+
+// RevokeOtherSessions 注销当前会话以外的所有会话
+func (s *sessionService) RevokeOtherSessions(userID uint, currentSessionID string) (int64, error) {
+	result := s.db.Model(&model.Session{}).
+		Where("user_id = ? AND session_id <> ? AND revoked_at IS NULL", userID, currentSessionID).
+		UpdateColumn("revoked_at", time.Now())
+	if result.Error != nil {
+		logrus.Errorf("注销用户 %d 的其他会话失败: %v", userID, result.Error)
+		return 0, result.Error
+	}
+
+	logrus.Infof("用户 %d 注销其他会话 %d 个", userID, result.RowsAffected)
+	return result.RowsAffected, nil
+}
+
+// CreateStreamTicket 为当前会话签发实时推送的一次性票据，同时清理已过期的票据
+func (s *sessionService) CreateStreamTicket(userID uint, sessionID string) (*model.StreamTicket, error) {
+	ttl, err := time.ParseDuration(s.cfg.StreamTicketTTL)
+	if err != nil || ttl <= 0 {
+		logrus.Warnf("无效的票据有效期 %q，使用默认值30s", s.cfg.StreamTicketTTL)
+		ttl = 30 * time.Second
+	}
+
+	value, err := utils.GenerateRandomString(32)
+	if err != nil {
+		logrus.Errorf("生成推送票据失败: %v", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.db.Where("expires_at < ?", now).Delete(&model.StreamTicket{}).Error; err != nil {
+		logrus.Errorf("清理过期的推送票据失败: %v", err)
+	}
+	ticket := &model.StreamTicket{Ticket: value, UserID: userID, SessionID: sessionID, ExpiresAt: now.Add(ttl)}
+	if err := s.db.Create(ticket).Error; err != nil {
+		logrus.Errorf("用户 %d 创建推送票据失败: %v", userID, err)
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// RedeemStreamTicket 使用推送票据，票据只能使用一次，过期或会话已失效时返回错误
+func (s *sessionService) RedeemStreamTicket(value string) (*model.StreamTicket, error) {
+	var ticket model.StreamTicket
+	if err := s.db.Where("ticket = ?", value).First(&ticket).Error; err != nil {
+		return nil, errors.New("无效的推送票据")
+	}
+
+	// 删除成功的请求才能使用票据，避免并发重复使用
+	result := s.db.Delete(&ticket)
+	if result.Error != nil {
+		logrus.Errorf("使用推送票据 %d 失败: %v", ticket.ID, result.Error)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || time.Now().After(ticket.ExpiresAt) {
+		return nil, errors.New("无效的推送票据")
+	}
+
+	if err := s.ValidateSession(ticket.SessionID, ticket.UserID); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/utils"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// sitemapMaxURLs 单个站点地图文件最多包含的地址数（协议上限）
+const sitemapMaxURLs = 50000
+
+// sitemapURLSet 站点地图
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL 站点地图中的地址
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// sitemapIndex 站点地图索引
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// sitemapSignature 文章数据的变化标识，发布、修改或删除文章后会改变
+type sitemapSignature struct {
+	count       int64
+	lastUpdated time.Time
+	lastDeleted time.Time
+}
+
+// sitemapCache 已生成的站点地图
+type sitemapCache struct {
+	signature    sitemapSignature
+	body         []byte
+	lastModified time.Time
+}
+
+// SitemapService 站点地图服务接口
+type SitemapService interface {
+	Sitemap(page int) ([]byte, time.Time, error)
+	RobotsTxt() string
+}
+
+// sitemapService 站点地图服务实现，结果按文章变化标识缓存
+type sitemapService struct {
+	db  *gorm.DB
+	cfg *config.Config
+
+	mu    sync.Mutex
+	cache map[int]sitemapCache
+}
+
+// NewSitemapService 创建站点地图服务实例
+func NewSitemapService(db *gorm.DB, cfg *config.Config) SitemapService {
+	return &sitemapService{db: db, cfg: cfg, cache: make(map[int]sitemapCache)}
+}
+
+// Sitemap 获取站点地图。page 为 0 时返回入口文件：文章数超过上限时为索引，否则直接为地址列表
+func (s *sitemapService) Sitemap(page int) ([]byte, time.Time, error) {
+	signature, err := s.signature()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[page]
+	s.mu.Unlock()
+	if ok && cached.signature == signature {
+		return cached.body, cached.lastModified, nil
+	}
+
+	pages := int((signature.count + sitemapMaxURLs - 1) / sitemapMaxURLs)
+	var body []byte
+	switch {
+	case page == 0 && pages > 1:
+		body, err = s.buildIndex(pages, signature.lastUpdated)
+	case page == 0:
+		body, err = s.buildURLSet(1)
+	case page > pages:
+		return nil, time.Time{}, errors.New("站点地图不存在")
+	default:
+		body, err = s.buildURLSet(page)
+	}
+	if err != nil {
+		logrus.Errorf("生成站点地图失败: %v", err)
+		return nil, time.Time{}, err
+	}
+
+	lastModified := signature.lastUpdated
+	if signature.lastDeleted.After(lastModified) {
+		lastModified = signature.lastDeleted
+	}
+
+	s.mu.Lock()
+	s.cache[page] = sitemapCache{signature: signature, body: body, lastModified: lastModified}
+	s.mu.Unlock()
+
+	logrus.Infof("站点地图已重新生成: 第 %d 页", page)
+	return body, lastModified, nil
+}
+
+// RobotsTxt 生成 robots.txt
+func (s *sitemapService) RobotsTxt() string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+
+	disallowed := false
+	for _, path := range strings.Split(s.cfg.RobotsDisallow, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			b.WriteString("Disallow: " + path + "\n")
+			disallowed = true
+		}
+	}
+	if !disallowed {
+		b.WriteString("Disallow:\n")
+	}
+
+	b.WriteString("\nSitemap: " + utils.AbsoluteURL(s.cfg, "/sitemap.xml") + "\n")
+	return b.String()
+}
+
+// signature 查询文章数据的变化标识
+func (s *sitemapService) signature() (sitemapSignature, error) {
+	var signature sitemapSignature
+	if err := s.db.Model(&model.Post{}).Where("visibility = ?", model.VisibilityPublic).Count(&signature.count).Error; err != nil {
+		logrus.Errorf("查询站点地图变化标识失败: %v", err)
+		return sitemapSignature{}, err
+	}
+
+	// 最近更新时间包含非公开文章，可见性变化同样会使缓存失效
+	var lastUpdated []time.Time
+	if err := s.db.Model(&model.Post{}).Order("updated_at DESC").Limit(1).Pluck("updated_at", &lastUpdated).Error; err != nil {
+		logrus.Errorf("查询站点地图变化标识失败: %v", err)
+		return sitemapSignature{}, err
+	}
+	if len(lastUpdated) > 0 {
+		signature.lastUpdated = lastUpdated[0]
+	}
+
+	var lastDeleted []time.Time
+	if err := s.db.Unscoped().Model(&model.Post{}).Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Limit(1).Pluck("deleted_at", &lastDeleted).Error; err != nil {
+		logrus.Errorf("查询站点地图变化标识失败: %v", err)
+		return sitemapSignature{}, err
+	}
+	if len(lastDeleted) > 0 {
+		signature.lastDeleted = lastDeleted[0]
+	}
+
+	return signature, nil
+}
+
+// buildIndex 生成站点地图索引
+func (s *sitemapService) buildIndex(pages int, lastUpdated time.Time) ([]byte, error) {
+	index := sitemapIndex{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for i := 1; i <= pages; i++ {
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{
+			Loc:     utils.AbsoluteURL(s.cfg, fmt.Sprintf("/sitemap/%d.xml", i)),
+			LastMod: lastUpdated.UTC().Format(time.RFC3339),
+		})
+	}
+	return marshalSitemap(index)
+}
+
+// buildURLSet 生成第 page 页的地址列表
+func (s *sitemapService) buildURLSet(page int) ([]byte, error) {
+	var posts []model.Post
+	if err := s.db.Select("id", "slug", "updated_at").Where("visibility = ?", model.VisibilityPublic).Order("id ASC").
+		Offset((page - 1) * sitemapMaxURLs).Limit(sitemapMaxURLs).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, post := range posts {
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:     utils.PostURL(s.cfg, post.ID, post.Slug),
+			LastMod: post.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return marshalSitemap(urlSet)
+}
+
+// marshalSitemap 序列化为带声明的 XML
+func marshalSitemap(v interface{}) ([]byte, error) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/spam"
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SpamService 垃圾内容过滤服务接口
+type SpamService interface {
+	CheckComment(content string, userID uint, userAgent, ip string) spam.Verdict
+	CheckSignup(username, email, userAgent, ip string) spam.Verdict
+	TrainComment(comment *model.Comment, isSpam bool)
+}
+
+// spamService 垃圾内容过滤服务实现
+type spamService struct {
+	db       *gorm.DB
+	pipeline *spam.Pipeline
+}
+
+// NewSpamService 创建垃圾内容过滤服务实例，根据配置组装检查流水线
+func NewSpamService(db *gorm.DB, cfg *config.Config) SpamService {
+	var checkers []spam.Checker
+	if cfg.SpamMaxLinks > 0 {
+		checkers = append(checkers, spam.NewLinkChecker(cfg.SpamMaxLinks))
+	}
+
+	blocklist, err := spam.LoadBlocklist(cfg.SpamBlocklistFile, strings.Split(cfg.SpamKeywords, ","))
+	if err != nil {
+		logrus.Errorf("加载屏蔽词失败，不启用屏蔽词检查: %v", err)
+	} else {
+		checkers = append(checkers, blocklist)
+	}
+
+	if cfg.SpamBayesMinDocs > 0 {
+		checkers = append(checkers, spam.NewBayes(&bayesStore{db: db}, int64(cfg.SpamBayesMinDocs)))
+	}
+	if cfg.AkismetAPIKey != "" {
+		checkers = append(checkers, spam.NewAkismet(cfg.AkismetEndpoint, cfg.AkismetAPIKey, cfg.SiteURL))
+	}
+
+	spamThreshold, reviewThreshold := cfg.SpamThreshold, cfg.SpamReviewThreshold
+	if spamThreshold <= 0 || spamThreshold > 1 {
+		spamThreshold = 0.9
+	}
+	if reviewThreshold <= 0 || reviewThreshold > spamThreshold {
+		reviewThreshold = spamThreshold
+	}
+
+	return &spamService{
+		db:       db,
+		pipeline: spam.NewPipeline(spamThreshold, reviewThreshold, checkers...),
+	}
+}
+
+// CheckComment 检查评论内容
+func (s *spamService) CheckComment(content string, userID uint, userAgent, ip string) spam.Verdict {
+	submission := s.commentSubmission(content, userID)
+	submission.UserAgent, submission.IP = userAgent, ip
+
+	verdict := s.pipeline.Check(context.Background(), submission)
+	if verdict.Action != spam.ActionAllow {
+		logrus.Infof("用户 %d 的评论被判定为 %s: %v", userID, verdict.Action, verdict.Reasons)
+	}
+	return verdict
+}
+
+// CheckSignup 检查注册信息
+func (s *spamService) CheckSignup(username, email, userAgent, ip string) spam.Verdict {
+	verdict := s.pipeline.Check(context.Background(), &spam.Submission{
+		Type:      spam.TypeSignup,
+		Author:    username,
+		Email:     email,
+		IP:        ip,
+		UserAgent: userAgent,
+	})
+	if verdict.Action != spam.ActionAllow {
+		logrus.Infof("注册用户 %s 被判定为 %s: %v", username, verdict.Action, verdict.Reasons)
+	}
+	return verdict
+}
+
+// TrainComment 根据版主的审核结果训练分类器
+func (s *spamService) TrainComment(comment *model.Comment, isSpam bool) {
+	s.pipeline.Train(context.Background(), s.commentSubmission(comment.Content, comment.UserID), isSpam)
+}
+
+// commentSubmission 构造评论的检查内容，作者信息从数据库读取
+func (s *spamService) commentSubmission(content string, userID uint) *spam.Submission {
+	var user model.User
+	s.db.Select("username", "email").First(&user, userID)
+	return &spam.Submission{
+		Type:    spam.TypeComment,
+		Author:  user.Username,
+		Email:   user.Email,
+		Content: content,
+	}
+}
+
+// bayesStore 基于数据库的贝叶斯词频存储
+type bayesStore struct {
+	db *gorm.DB
+}
+
+// Counts 读取词频及两类文档总数，总数保存在 Token 为空的记录中
+func (b *bayesStore) Counts(tokens []string) (map[string]spam.TokenCounts, spam.TokenCounts, error) {
+	var rows []model.SpamToken
+	if err := b.db.Where("token IN ?", append(tokens, "")).Find(&rows).Error; err != nil {
+		return nil, spam.TokenCounts{}, err
+	}
+
+	counts := make(map[string]spam.TokenCounts, len(rows))
+	var docs spam.TokenCounts
+	for _, row := range rows {
+		if row.Token == "" {
+			docs = spam.TokenCounts{Spam: row.Spam, Ham: row.Ham}
+			continue
+		}
+		counts[row.Token] = spam.TokenCounts{Spam: row.Spam, Ham: row.Ham}
+	}
+	return counts, docs, nil
+}
+
+// Learn 在一个事务中累加文档的所有词及文档总数
+func (b *bayesStore) Learn(tokens []string, isSpam bool) error {
+	column := "ham"
+	if isSpam {
+		column = "spam"
+	}
+
+	return b.db.Transaction(func(tx *gorm.DB) error {
+		for _, token := range append(tokens, "") {
+			row := model.SpamToken{Token: token}
+			if isSpam {
+				row.Spam = 1
+			} else {
+				row.Ham = 1
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "token"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr(column + " + 1")}),
+			}).Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TrashService 回收站服务接口
+type TrashService interface {
+	ListPosts(userID uint, all bool, page, pageSize int) ([]model.Post, int64, error)
+	ListComments(userID uint, all bool, page, pageSize int) ([]model.Comment, int64, error)
+	RestorePost(id, userID uint, asAdmin bool) error
+	RestoreComment(id, userID uint, asAdmin bool) error
+	PurgePost(id, userID uint, asAdmin bool) error
+	PurgeComment(id, userID uint, asAdmin bool) error
+	PurgeExpired() (posts, comments int64, err error)
+	Start()
+	Stop()
+}
+
+// trashService 回收站服务实现
+type trashService struct {
+	db  *gorm.DB
+	cfg *config.Config
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTrashService 创建回收站服务实例
+func NewTrashService(db *gorm.DB, cfg *config.Config) TrashService {
+	return &trashService{db: db, cfg: cfg}
+}
+
+// ListPosts 获取回收站中的文章，all 为 true 时返回所有用户的文章（管理员）
+func (s *trashService) ListPosts(userID uint, all bool, page, pageSize int) ([]model.Post, int64, error) {
+	var posts []model.Post
+	var total int64
+
+	query := s.db.Unscoped().Model(&model.Post{}).Where("deleted_at IS NOT NULL")
+	if !all {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		logrus.Errorf("计算回收站文章总数失败: %v", err)
+		return nil, 0, err
+	}
+	if err := query.Preload("User").Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&posts).Error; err != nil {
+		logrus.Errorf("获取回收站文章失败: %v", err)
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+// ListComments 获取回收站中的评论，all 为 true 时返回所有用户的评论（管理员）
+func (s *trashService) ListComments(userID uint, all bool, page, pageSize int) ([]model.Comment, int64, error) {
+	var comments []model.Comment
+	var total int64
+
+	query := s.db.Unscoped().Model(&model.Comment{}).Where("deleted_at IS NOT NULL")
+	if !all {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		logrus.Errorf("计算回收站评论总数失败: %v", err)
+		return nil, 0, err
+	}
+	if err := query.Preload("User").Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&comments).Error; err != nil {
+		logrus.Errorf("获取回收站评论失败: %v", err)
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// RestorePost 恢复文章，同时恢复随文章一起删除的评论
+func (s *trashService) RestorePost(id, userID uint, asAdmin bool) error {
+	post, err := s.trashedPost(id, userID, asAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Comment{}).
+			Where("post_id = ? AND deleted_at = ?", id, post.DeletedAt.Time).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(post).UpdateColumn("deleted_at", nil).Error
+	})
+	if err != nil {
+		logrus.Errorf("恢复文章 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 恢复文章成功: %d", userID, id)
+	return nil
+}
+
+// RestoreComment 恢复评论，所属文章仍在回收站时不能恢复
+func (s *trashService) RestoreComment(id, userID uint, asAdmin bool) error {
+	comment, err := s.trashedComment(id, userID, asAdmin)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.db.Model(&model.Post{}).Where("id = ?", comment.PostID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("所属文章已删除，请先恢复文章")
+	}
+
+	if err := s.db.Unscoped().Model(comment).UpdateColumn("deleted_at", nil).Error; err != nil {
+		logrus.Errorf("恢复评论 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 恢复评论成功: %d", userID, id)
+	return nil
+}
+
+// PurgePost 永久删除回收站中的文章及其关联数据
+func (s *trashService) PurgePost(id, userID uint, asAdmin bool) error {
+	if _, err := s.trashedPost(id, userID, asAdmin); err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return purgePosts(tx, []uint{id})
+	}); err != nil {
+		logrus.Errorf("永久删除文章 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 永久删除文章: %d", userID, id)
+	return nil
+}
+
+// PurgeComment 永久删除回收站中的评论
+func (s *trashService) PurgeComment(id, userID uint, asAdmin bool) error {
+	comment, err := s.trashedComment(id, userID, asAdmin)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := purgeCommentData(tx, []uint{id}); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(comment).Error
+	}); err != nil {
+		logrus.Errorf("永久删除评论 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 永久删除评论: %d", userID, id)
+	return nil
+}
+
+// PurgeExpired 永久删除超过保留天数的文章和评论
+func (s *trashService) PurgeExpired() (posts, comments int64, err error) {
+	if s.cfg.TrashRetentionDays <= 0 {
+		return 0, 0, nil
+	}
+	before := time.Now().AddDate(0, 0, -s.cfg.TrashRetentionDays)
+
+	// 按ID分批删除，每批使用独立事务，避免长时间锁表
+	expiredPosts := s.db.Unscoped().Model(&model.Post{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+	posts, err = purgeInBatches(expiredPosts, purgePosts)
+	if err == nil {
+		expiredComments := s.db.Unscoped().Model(&model.Comment{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		comments, err = purgeInBatches(expiredComments, func(tx *gorm.DB, ids []uint) error {
+			if err := purgeCommentData(tx, ids); err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Comment{}).Error
+		})
+	}
+	if err != nil {
+		logrus.Errorf("清理回收站失败: %v", err)
+		return 0, 0, err
+	}
+
+	if posts > 0 || comments > 0 {
+		logrus.Infof("清理回收站: 永久删除文章 %d 篇，评论 %d 条", posts, comments)
+	}
+	return posts, comments, nil
+}
+
+// Start 启动回收站定期清理任务，每小时执行一次
+func (s *trashService) Start() {
+	if s.cfg.TrashRetentionDays <= 0 {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		s.PurgeExpired()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.PurgeExpired()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止回收站定期清理任务
+func (s *trashService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+}
+
+// trashedPost 获取回收站中的文章并检查权限
+func (s *trashService) trashedPost(id, userID uint, asAdmin bool) (*model.Post, error) {
+	var post model.Post
+	if err := s.db.Unscoped().Where("deleted_at IS NOT NULL").First(&post, id).Error; err != nil {
+		return nil, errors.New("文章不在回收站中")
+	}
+	if !asAdmin && post.UserID != userID {
+		logrus.Warnf("用户 %d 尝试操作不属于自己的已删除文章 %d", userID, id)
+		return nil, errors.New("没有权限操作此文章")
+	}
+	return &post, nil
+}
+
+// trashedComment 获取回收站中的评论并检查权限
+func (s *trashService) trashedComment(id, userID uint, asAdmin bool) (*model.Comment, error) {
+	var comment model.Comment
+	if err := s.db.Unscoped().Where("deleted_at IS NOT NULL").First(&comment, id).Error; err != nil {
+		return nil, errors.New("评论不在回收站中")
+	}
+	if !asAdmin && comment.UserID != userID {
+		logrus.Warnf("用户 %d 尝试操作不属于自己的已删除评论 %d", userID, id)
+		return nil, errors.New("没有权限操作此评论")
+	}
+	return &comment, nil
+}
+
+// purgeBatchSize 清理回收站时每批删除的记录数
+const purgeBatchSize = 500
+
+// purgeInBatches 按ID升序分批读取 query 匹配的记录，每批在独立事务中调用 purge，返回删除的记录数
+func purgeInBatches(query *gorm.DB, purge func(tx *gorm.DB, ids []uint) error) (int64, error) {
+	var total int64
+	var lastID uint
+	for {
+		var ids []uint
+		if err := query.Session(&gorm.Session{}).Where("id > ?", lastID).
+			Order("id").Limit(purgeBatchSize).Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		if err := query.Session(&gorm.Session{NewDB: true}).Transaction(func(tx *gorm.DB) error {
+			return purge(tx, ids)
+		}); err != nil {
+			return total, err
+		}
+		total += int64(len(ids))
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// purgePosts 永久删除文章及其评论（含关联数据）、slug、表态、收藏、媒体引用、阅读统计和通知
+func purgePosts(tx *gorm.DB, postIDs []uint) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+	comments := tx.Unscoped().Model(&model.Comment{}).Select("id").Where("post_id IN ?", postIDs)
+	if err := purgeCommentData(tx, comments); err != nil {
+		return err
+	}
+	related := []interface{}{
+		&model.Comment{},
+		&model.PostSlug{},
+		&model.Reaction{},
+		&model.Bookmark{},
+		&model.PostMedia{},
+		&model.PostViewDaily{},
+		&model.PostReferrer{},
+		&model.Notification{},
+	}
+	for _, m := range related {
+		if err := tx.Unscoped().Where("post_id IN ?", postIDs).Delete(m).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Where("id IN ?", postIDs).Delete(&model.Post{}).Error
+}
+
+// purgeCommentData 永久删除评论的历史版本、举报记录和通知，commentIDs 可以是ID列表或子查询
+func purgeCommentData(tx *gorm.DB, commentIDs interface{}) error {
+	for _, m := range []interface{}{&model.CommentRevision{}, &model.CommentFlag{}, &model.Notification{}} {
+		if err := tx.Where("comment_id IN (?)", commentIDs).Delete(m).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
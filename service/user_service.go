@@ -1,35 +1,59 @@
 package service
 
 import (
+	"blog-backend/config"
 	"blog-backend/model"
-	// "blog-backend/utils"
+	"blog-backend/spam"
+	"blog-backend/utils"
 	"errors"
+	"strings"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 // UserService 用户服务接口
 type UserService interface {
-	CreateUser(username, email, password string) (*model.User, error)
+	CreateUser(username, email, password, inviteCode, userAgent, ip string) (*model.User, error)
 	Login(username, password string) (*model.User, error)
 	GetUserByID(id uint) (*model.User, error)
 	GetUserByUsername(username string) (*model.User, error)
+	ChangePassword(id uint, oldPassword, newPassword string) error
+	ListPendingUsers(page, pageSize int) ([]model.User, int64, error)
+	ApproveUser(id uint) error
+	RejectUser(id uint) error
+	SetUserRole(id uint, role string) error
+	PromoteAdmins(usernames []string) error
 }
 
 // userService 用户服务实现
 type userService struct {
-	db *gorm.DB
+	db          *gorm.DB
+	cfg         *config.Config
+	policy      *utils.PasswordPolicy
+	spamService SpamService
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(db *gorm.DB) UserService {
-	return &userService{db: db}
+func NewUserService(db *gorm.DB, cfg *config.Config, spamService SpamService) UserService {
+	return &userService{db: db, cfg: cfg, policy: utils.NewPasswordPolicy(cfg), spamService: spamService}
 }
 
-// CreateUser 创建新用户
-func (s *userService) CreateUser(username, email, password string) (*model.User, error) {
+// CreateUser 创建新用户，按注册模式校验邀请码或进入待审核状态，可疑注册需要人工审核
+func (s *userService) CreateUser(username, email, password, inviteCode, userAgent, ip string) (*model.User, error) {
+	// 邀请注册模式下必须提供邀请码
+	if s.cfg.RegistrationMode == config.RegistrationInvite && inviteCode == "" {
+		logrus.Warnf("邀请注册模式下未提供邀请码: %s", username)
+		return nil, errors.New("当前仅限邀请注册")
+	}
+
+	// 校验密码策略
+	if err := s.policy.Validate(password, username); err != nil {
+		logrus.Warnf("用户 %s 的密码不符合策略: %v", username, err)
+		return nil, err
+	}
+
 	// 检查用户名是否已存在
 	var existingUser model.User
 	if err := s.db.Where("username = ?", username).First(&existingUser).Error; err == nil {
@@ -43,14 +67,36 @@ func (s *userService) CreateUser(username, email, password string) (*model.User,
 		return nil, errors.New("邮箱已存在")
 	}
 
+	// 垃圾注册检查
+	verdict := s.spamService.CheckSignup(username, email, userAgent, ip)
+	if verdict.Action == spam.ActionSpam {
+		return nil, errors.New("注册信息被识别为垃圾信息")
+	}
+
 	// 创建新用户
 	user := &model.User{
 		Username: username,
 		Email:    email,
-		Password: password, // 密码会在BeforeSave钩子中加密
+		Role:     model.RoleUser,
+		Status:   model.UserStatusActive,
+	}
+	if err := user.SetPassword(password); err != nil {
+		logrus.Errorf("加密用户 %s 的密码失败: %v", username, err)
+		return nil, err
+	}
+	if s.cfg.RegistrationMode == config.RegistrationApproval || verdict.Action == spam.ActionReview {
+		user.Status = model.UserStatusPending
 	}
 
-	if err := s.db.Create(user).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if s.cfg.RegistrationMode == config.RegistrationInvite {
+			if err := consumeInviteCode(tx, inviteCode); err != nil {
+				return err
+			}
+		}
+		return tx.Create(user).Error
+	})
+	if err != nil {
 		logrus.Errorf("创建用户失败: %v", err)
 		return nil, err
 	}
@@ -69,11 +115,27 @@ func (s *userService) Login(username, password string) (*model.User, error) {
 	}
 
 	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	ok, needsRehash := user.CheckPassword(password)
+	if !ok {
 		logrus.Warnf("用户 %s 密码错误", username)
 		return nil, errors.New("用户名或密码错误")
 	}
 
+	// 旧算法或旧参数的哈希在登录成功后透明升级
+	if needsRehash {
+		if err := s.updatePassword(&user, password); err != nil {
+			logrus.Errorf("用户 %s 密码哈希升级失败: %v", username, err)
+		} else {
+			logrus.Infof("用户 %s 密码哈希已升级", username)
+		}
+	}
+
+	// 检查账号状态
+	if err := checkUserStatus(&user); err != nil {
+		logrus.Warnf("用户 %s 登录被拒绝: %v", username, err)
+		return nil, err
+	}
+
 	logrus.Infof("用户登录成功: %s", username)
 	return &user, nil
 }
@@ -97,3 +159,164 @@ func (s *userService) GetUserByUsername(username string) (*model.User, error) {
 	}
 	return &user, nil
 }
+
+// ChangePassword 修改密码，需要校验原密码
+func (s *userService) ChangePassword(id uint, oldPassword, newPassword string) error {
+	var user model.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		logrus.Errorf("修改用户 %d 密码失败: 用户不存在 - %v", id, err)
+		return errors.New("用户不存在")
+	}
+
+	if ok, _ := user.CheckPassword(oldPassword); !ok {
+		logrus.Warnf("用户 %d 修改密码时原密码错误", id)
+		return errors.New("原密码错误")
+	}
+
+	// 校验密码策略
+	if err := s.policy.Validate(newPassword, user.Username); err != nil {
+		logrus.Warnf("用户 %d 的新密码不符合策略: %v", id, err)
+		return err
+	}
+
+	if err := s.updatePassword(&user, newPassword); err != nil {
+		logrus.Errorf("修改用户 %d 密码失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 修改密码成功", id)
+	return nil
+}
+
+// updatePassword 加密并只更新密码列，所有密码变更都经过此方法
+func (s *userService) updatePassword(user *model.User, password string) error {
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	return s.db.Model(user).UpdateColumn("password", user.Password).Error
+}
+
+// ListPendingUsers 获取待审核用户列表（分页）
+func (s *userService) ListPendingUsers(page, pageSize int) ([]model.User, int64, error) {
+	var users []model.User
+	var total int64
+
+	// 计算总记录数
+	if err := s.db.Model(&model.User{}).Where("status = ?", model.UserStatusPending).Count(&total).Error; err != nil {
+		logrus.Errorf("计算待审核用户总数失败: %v", err)
+		return nil, 0, err
+	}
+
+	// 计算偏移量
+	offset := (page - 1) * pageSize
+
+	// 获取分页数据
+	if err := s.db.Where("status = ?", model.UserStatusPending).Offset(offset).Limit(pageSize).Order("created_at ASC").Find(&users).Error; err != nil {
+		logrus.Errorf("获取待审核用户列表失败: %v", err)
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// ApproveUser 审核通过用户
+func (s *userService) ApproveUser(id uint) error {
+	return s.reviewUser(id, model.UserStatusActive)
+}
+
+// RejectUser 拒绝用户注册
+func (s *userService) RejectUser(id uint) error {
+	return s.reviewUser(id, model.UserStatusRejected)
+}
+
+// reviewUser 更新待审核用户的状态
+func (s *userService) reviewUser(id uint, status string) error {
+	var user model.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		logrus.Errorf("审核用户 %d 失败: 用户不存在 - %v", id, err)
+		return errors.New("用户不存在")
+	}
+
+	if user.Status != model.UserStatusPending {
+		logrus.Warnf("审核用户 %d 失败: 当前状态为 %s", id, user.Status)
+		return errors.New("用户不在待审核状态")
+	}
+
+	if err := s.db.Model(&user).UpdateColumn("status", status).Error; err != nil {
+		logrus.Errorf("审核用户 %d 失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 审核完成: %s", id, status)
+	return nil
+}
+
+// SetUserRole 设置用户角色
+func (s *userService) SetUserRole(id uint, role string) error {
+	if role != model.RoleUser && role != model.RoleModerator && role != model.RoleAdmin {
+		return errors.New("无效的角色")
+	}
+
+	var user model.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		logrus.Errorf("设置用户 %d 角色失败: 用户不存在 - %v", id, err)
+		return errors.New("用户不存在")
+	}
+
+	if err := s.db.Model(&user).UpdateColumn("role", role).Error; err != nil {
+		logrus.Errorf("设置用户 %d 角色失败: %v", id, err)
+		return err
+	}
+
+	logrus.Infof("用户 %d 角色已设置为 %s", id, role)
+	return nil
+}
+
+// PromoteAdmins 将指定用户名的用户提升为管理员，用于初始化管理员账号
+func (s *userService) PromoteAdmins(usernames []string) error {
+	var names []string
+	for _, name := range usernames {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	result := s.db.Model(&model.User{}).Where("username IN ?", names).
+		UpdateColumns(map[string]interface{}{"role": model.RoleAdmin, "status": model.UserStatusActive})
+	if result.Error != nil {
+		logrus.Errorf("提升管理员失败: %v", result.Error)
+		return result.Error
+	}
+
+	logrus.Infof("已提升 %d 个管理员账号", result.RowsAffected)
+	return nil
+}
+
+// checkUserStatus 检查账号状态是否允许登录
+func checkUserStatus(user *model.User) error {
+	switch user.Status {
+	case model.UserStatusPending:
+		return errors.New("账号正在等待审核")
+	case model.UserStatusRejected:
+		return errors.New("账号审核未通过")
+	}
+	return nil
+}
+
+// consumeInviteCode 校验并使用一次邀请码
+func consumeInviteCode(tx *gorm.DB, code string) error {
+	result := tx.Model(&model.InviteCode{}).
+		Where("code = ? AND (max_uses = 0 OR used_count < max_uses) AND (expires_at IS NULL OR expires_at > ?)", code, time.Now()).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		logrus.Warnf("邀请码无效或已失效: %s", code)
+		return errors.New("邀请码无效或已失效")
+	}
+	return nil
+}
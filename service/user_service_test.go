@@ -0,0 +1,71 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建内存数据库并迁移数据表。
+// 每个连接都有独立的内存数据库，因此限制为单个连接
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取测试数据库连接失败: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := model.AutoMigrate(db); err != nil {
+		t.Fatalf("迁移测试数据库失败: %v", err)
+	}
+	return db
+}
+
+// TestSaveLoadedUserKeepsPassword 加载并保存用户后密码哈希不应被再次加密
+func TestSaveLoadedUserKeepsPassword(t *testing.T) {
+	db := newTestDB(t)
+	cfg := &config.Config{
+		RegistrationMode:  config.RegistrationOpen,
+		PasswordMinLength: 8,
+	}
+	svc := NewUserService(db, cfg, NewSpamService(db, cfg))
+
+	created, err := svc.CreateUser("alice", "alice@example.com", "s3cret-pass", "", "", "")
+	if err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	var loaded model.User
+	if err := db.First(&loaded, created.ID).Error; err != nil {
+		t.Fatalf("加载用户失败: %v", err)
+	}
+	hash := loaded.Password
+
+	loaded.Email = "alice@example.org"
+	if err := db.Save(&loaded).Error; err != nil {
+		t.Fatalf("保存用户失败: %v", err)
+	}
+
+	var reloaded model.User
+	if err := db.First(&reloaded, created.ID).Error; err != nil {
+		t.Fatalf("重新加载用户失败: %v", err)
+	}
+	if reloaded.Password != hash {
+		t.Fatalf("保存后密码哈希发生变化: %q -> %q", hash, reloaded.Password)
+	}
+
+	if _, err := svc.Login("alice", "s3cret-pass"); err != nil {
+		t.Fatalf("保存用户后无法登录: %v", err)
+	}
+}
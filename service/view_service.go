@@ -0,0 +1,311 @@
+package service
+
+import (
+	"blog-backend/config"
+	"blog-backend/model"
+	"blog-backend/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DailyViews 某日阅读数
+type DailyViews struct {
+	Day   string `json:"day"`
+	Views int64  `json:"views"`
+}
+
+// ReferrerViews 来源阅读数
+type ReferrerViews struct {
+	Referrer string `json:"referrer"`
+	Views    int64  `json:"views"`
+}
+
+// PostViews 文章阅读数
+type PostViews struct {
+	PostID uint   `json:"post_id"`
+	Title  string `json:"title"`
+	Slug   string `json:"slug"`
+	Views  int64  `json:"views"`
+}
+
+// ViewAnalytics 阅读统计结果
+type ViewAnalytics struct {
+	TotalViews int64           `json:"total_views"`
+	Daily      []DailyViews    `json:"daily"`
+	Referrers  []ReferrerViews `json:"referrers"`
+	TopPosts   []PostViews     `json:"top_posts,omitempty"`
+}
+
+// ViewService 阅读统计服务接口
+type ViewService interface {
+	RecordView(postID uint, ip, userAgent, referer string)
+	Start()
+	Stop()
+	Flush() error
+	AuthorAnalytics(userID uint, days int) (*ViewAnalytics, error)
+	PostAnalytics(postID, userID uint, days int) (*ViewAnalytics, error)
+}
+
+// viewKey 每日阅读数的缓冲键
+type viewKey struct {
+	postID uint
+	day    string
+}
+
+// referrerKey 来源统计的缓冲键
+type referrerKey struct {
+	postID   uint
+	day      string
+	referrer string
+}
+
+// viewService 阅读统计服务实现，阅读数先缓存在内存中，定期批量写入数据库
+type viewService struct {
+	db            *gorm.DB
+	flushInterval time.Duration
+	dedupWindow   time.Duration
+
+	mu        sync.Mutex
+	views     map[viewKey]int64
+	referrers map[referrerKey]int64
+	seen      map[string]time.Time // 访客最近一次被计数的时间，用于去重
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewViewService 创建阅读统计服务实例
+func NewViewService(db *gorm.DB, cfg *config.Config) ViewService {
+	flushInterval, err := time.ParseDuration(cfg.ViewFlushInterval)
+	if err != nil || flushInterval <= 0 {
+		logrus.Warnf("无效的阅读数写入间隔 %q，使用默认值30s", cfg.ViewFlushInterval)
+		flushInterval = 30 * time.Second
+	}
+	dedupWindow, err := time.ParseDuration(cfg.ViewDedupWindow)
+	if err != nil || dedupWindow < 0 {
+		logrus.Warnf("无效的阅读去重窗口 %q，使用默认值30m", cfg.ViewDedupWindow)
+		dedupWindow = 30 * time.Minute
+	}
+
+	return &viewService{
+		db:            db,
+		flushInterval: flushInterval,
+		dedupWindow:   dedupWindow,
+		views:         make(map[viewKey]int64),
+		referrers:     make(map[referrerKey]int64),
+		seen:          make(map[string]time.Time),
+	}
+}
+
+// RecordView 记录一次阅读，爬虫和去重窗口内的重复访问不计数
+func (s *viewService) RecordView(postID uint, ip, userAgent, referer string) {
+	if utils.IsBot(userAgent) {
+		return
+	}
+
+	// 访客标识为 IP 与 User-Agent 的哈希，不保存原始信息
+	sum := sha256.Sum256([]byte(ip + "|" + userAgent))
+	visitor := strconv.FormatUint(uint64(postID), 10) + ":" + hex.EncodeToString(sum[:12])
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.seen[visitor]; ok && now.Sub(last) < s.dedupWindow {
+		return
+	}
+	s.seen[visitor] = now
+
+	day := now.Format("2006-01-02")
+	s.views[viewKey{postID: postID, day: day}]++
+	s.referrers[referrerKey{postID: postID, day: day, referrer: utils.ReferrerHost(referer)}]++
+}
+
+// Start 启动定期写入任务
+func (s *viewService) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					logrus.Errorf("写入阅读统计失败: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止定期写入任务，并写入剩余的阅读数
+func (s *viewService) Stop() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	if err := s.Flush(); err != nil {
+		logrus.Errorf("写入阅读统计失败: %v", err)
+	}
+}
+
+// Flush 将缓冲的阅读数批量写入数据库，写入失败时数据放回缓冲区等待下次写入
+func (s *viewService) Flush() error {
+	s.mu.Lock()
+	views, referrers := s.views, s.referrers
+	s.views = make(map[viewKey]int64)
+	s.referrers = make(map[referrerKey]int64)
+
+	// 清理已过去重窗口的访客记录
+	now := time.Now()
+	for visitor, last := range s.seen {
+		if now.Sub(last) >= s.dedupWindow {
+			delete(s.seen, visitor)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(views) == 0 {
+		return nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for key, n := range views {
+			row := model.PostViewDaily{PostID: key.postID, Day: key.day, Views: n}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + ?", n)}),
+			}).Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		for key, n := range referrers {
+			row := model.PostReferrer{PostID: key.postID, Day: key.day, Referrer: key.referrer, Views: n}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "post_id"}, {Name: "day"}, {Name: "referrer"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + ?", n)}),
+			}).Create(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// 放回缓冲区
+		s.mu.Lock()
+		for key, n := range views {
+			s.views[key] += n
+		}
+		for key, n := range referrers {
+			s.referrers[key] += n
+		}
+		s.mu.Unlock()
+		return err
+	}
+
+	logrus.Debugf("写入阅读统计 %d 条", len(views))
+	return nil
+}
+
+// AuthorAnalytics 获取作者所有文章最近若干天的阅读统计
+func (s *viewService) AuthorAnalytics(userID uint, days int) (*ViewAnalytics, error) {
+	postIDs := s.db.Model(&model.Post{}).Select("id").Where("user_id = ?", userID)
+	analytics, err := s.analytics(postIDs, days)
+	if err != nil {
+		logrus.Errorf("获取用户 %d 的阅读统计失败: %v", userID, err)
+		return nil, err
+	}
+
+	// 阅读最多的文章
+	since := sinceDay(days)
+	if err := s.db.Model(&model.PostViewDaily{}).
+		Select("post_view_dailies.post_id, posts.title, posts.slug, SUM(post_view_dailies.views) AS views").
+		Joins("JOIN posts ON posts.id = post_view_dailies.post_id AND posts.deleted_at IS NULL").
+		Where("posts.user_id = ? AND post_view_dailies.day >= ?", userID, since).
+		Group("post_view_dailies.post_id, posts.title, posts.slug").
+		Order("views DESC").
+		Limit(10).
+		Scan(&analytics.TopPosts).Error; err != nil {
+		logrus.Errorf("获取用户 %d 的热门文章失败: %v", userID, err)
+		return nil, err
+	}
+
+	return analytics, nil
+}
+
+// PostAnalytics 获取单篇文章最近若干天的阅读统计，仅作者可查看
+func (s *viewService) PostAnalytics(postID, userID uint, days int) (*ViewAnalytics, error) {
+	var post model.Post
+	if err := s.db.First(&post, postID).Error; err != nil {
+		return nil, errors.New("文章不存在")
+	}
+	if post.UserID != userID {
+		return nil, errors.New("无权查看该文章的统计")
+	}
+
+	analytics, err := s.analytics([]uint{postID}, days)
+	if err != nil {
+		logrus.Errorf("获取文章 %d 的阅读统计失败: %v", postID, err)
+		return nil, err
+	}
+	return analytics, nil
+}
+
+// analytics 统计指定文章范围内的每日阅读数与来源，postIDs 可以是ID列表或子查询，回收站中的文章不计入
+func (s *viewService) analytics(postIDs interface{}, days int) (*ViewAnalytics, error) {
+	since := sinceDay(days)
+	postIDs = s.db.Model(&model.Post{}).Select("id").Where("id IN (?)", postIDs)
+	analytics := &ViewAnalytics{Daily: []DailyViews{}, Referrers: []ReferrerViews{}}
+
+	if err := s.db.Model(&model.PostViewDaily{}).
+		Select("day, SUM(views) AS views").
+		Where("post_id IN (?) AND day >= ?", postIDs, since).
+		Group("day").
+		Order("day").
+		Scan(&analytics.Daily).Error; err != nil {
+		return nil, err
+	}
+	// 补齐没有阅读的日期
+	counts := make(map[string]int64, len(analytics.Daily))
+	for _, d := range analytics.Daily {
+		counts[d.Day] = d.Views
+		analytics.TotalViews += d.Views
+	}
+	analytics.Daily = analytics.Daily[:0]
+	start := time.Now().AddDate(0, 0, -(days - 1))
+	for i := 0; i < days; i++ {
+		day := start.AddDate(0, 0, i).Format("2006-01-02")
+		analytics.Daily = append(analytics.Daily, DailyViews{Day: day, Views: counts[day]})
+	}
+
+	if err := s.db.Model(&model.PostReferrer{}).
+		Select("referrer, SUM(views) AS views").
+		Where("post_id IN (?) AND day >= ?", postIDs, since).
+		Group("referrer").
+		Order("views DESC").
+		Limit(20).
+		Scan(&analytics.Referrers).Error; err != nil {
+		return nil, err
+	}
+
+	return analytics, nil
+}
+
+// sinceDay 统计范围的起始日期（含当天共 days 天）
+func sinceDay(days int) string {
+	return time.Now().AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+}
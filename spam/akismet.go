@@ -0,0 +1,100 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Akismet Akismet 兼容的垃圾内容检查服务
+type Akismet struct {
+	endpoint string
+	apiKey   string
+	blog     string
+	client   *http.Client
+}
+
+// NewAkismet 创建 Akismet 检查器，endpoint 为接口地址（如 https://rest.akismet.com/1.1），blog 为站点地址
+func NewAkismet(endpoint, apiKey, blog string) *Akismet {
+	return &Akismet{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		apiKey:   apiKey,
+		blog:     blog,
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Name 检查器名称
+func (a *Akismet) Name() string {
+	return "akismet"
+}
+
+// Check 调用 comment-check 接口，返回 true 表示垃圾内容
+func (a *Akismet) Check(ctx context.Context, s *Submission) (Result, error) {
+	body, header, err := a.call(ctx, "comment-check", s)
+	if err != nil {
+		return Result{}, err
+	}
+
+	switch body {
+	case "true":
+		reason := "判定为垃圾内容"
+		if header.Get("X-akismet-pro-tip") == "discard" {
+			reason = "判定为明显的垃圾内容"
+		}
+		return Result{Score: 1, Reason: reason}, nil
+	case "false":
+		return Result{}, nil
+	default:
+		return Result{}, fmt.Errorf("无法识别的响应 %q: %s", body, header.Get("X-akismet-debug-help"))
+	}
+}
+
+// Train 调用 submit-spam 或 submit-ham 接口反馈人工审核结果
+func (a *Akismet) Train(ctx context.Context, s *Submission, isSpam bool) error {
+	method := "submit-ham"
+	if isSpam {
+		method = "submit-spam"
+	}
+	_, _, err := a.call(ctx, method, s)
+	return err
+}
+
+// call 以表单方式调用 Akismet 接口，返回去除空白的响应内容
+func (a *Akismet) call(ctx context.Context, method string, s *Submission) (string, http.Header, error) {
+	form := url.Values{
+		"api_key":              {a.apiKey},
+		"blog":                 {a.blog},
+		"user_ip":              {s.IP},
+		"user_agent":           {s.UserAgent},
+		"comment_type":         {s.Type},
+		"comment_author":       {s.Author},
+		"comment_author_email": {s.Email},
+		"comment_content":      {s.Content},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint+"/"+method, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("%s 返回状态码 %d", method, resp.StatusCode)
+	}
+	return strings.TrimSpace(string(data)), resp.Header, nil
+}
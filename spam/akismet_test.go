@@ -0,0 +1,87 @@
+package spam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newAkismetStub 启动本地 Akismet 桩服务，内容包含 viagra 时判定为垃圾内容，并记录收到的反馈
+func newAkismetStub(t *testing.T, submitted *[]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("api_key") != "test-key" {
+			w.Header().Set("X-akismet-debug-help", "invalid key")
+			w.Write([]byte("invalid"))
+			return
+		}
+		switch r.URL.Path {
+		case "/comment-check":
+			if strings.Contains(r.PostForm.Get("comment_content"), "viagra") {
+				w.Header().Set("X-akismet-pro-tip", "discard")
+				w.Write([]byte("true"))
+				return
+			}
+			w.Write([]byte("false"))
+		case "/submit-spam", "/submit-ham":
+			*submitted = append(*submitted, r.URL.Path+":"+r.PostForm.Get("comment_content"))
+			w.Write([]byte("Thanks for making the web a better place."))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestAkismetCheck 桩服务返回 true 时判定为垃圾内容，false 时放行，无法识别的响应返回错误
+func TestAkismetCheck(t *testing.T) {
+	var submitted []string
+	srv := newAkismetStub(t, &submitted)
+	ctx := context.Background()
+
+	akismet := NewAkismet(srv.URL+"/", "test-key", "http://blog.example.com")
+	result, err := akismet.Check(ctx, &Submission{Type: TypeComment, Content: "buy viagra now"})
+	if err != nil {
+		t.Fatalf("检查垃圾内容失败: %v", err)
+	}
+	if result.Score != 1 {
+		t.Errorf("垃圾内容得分 = %v, 期望 1", result.Score)
+	}
+
+	result, err = akismet.Check(ctx, &Submission{Type: TypeComment, Content: "写得很好"})
+	if err != nil {
+		t.Fatalf("检查正常内容失败: %v", err)
+	}
+	if result.Score != 0 {
+		t.Errorf("正常内容得分 = %v, 期望 0", result.Score)
+	}
+
+	invalid := NewAkismet(srv.URL, "wrong-key", "http://blog.example.com")
+	if _, err := invalid.Check(ctx, &Submission{Content: "hello"}); err == nil {
+		t.Error("无效的密钥应返回错误")
+	}
+
+	// 流水线中出错的检查器被跳过
+	verdict := NewPipeline(0.9, 0.5, invalid, akismet).Check(ctx, &Submission{Content: "viagra"})
+	if verdict.Action != ActionSpam {
+		t.Errorf("流水线结论 = %s, 期望 %s", verdict.Action, ActionSpam)
+	}
+}
+
+// TestAkismetTrain 人工审核结果通过 submit-spam 和 submit-ham 反馈
+func TestAkismetTrain(t *testing.T) {
+	var submitted []string
+	srv := newAkismetStub(t, &submitted)
+
+	pipeline := NewPipeline(0.9, 0.5, NewLinkChecker(3), NewAkismet(srv.URL, "test-key", "http://blog.example.com"))
+	pipeline.Train(context.Background(), &Submission{Content: "spam"}, true)
+	pipeline.Train(context.Background(), &Submission{Content: "ham"}, false)
+
+	want := []string{"/submit-spam:spam", "/submit-ham:ham"}
+	if strings.Join(submitted, ",") != strings.Join(want, ",") {
+		t.Errorf("反馈记录 = %v, 期望 %v", submitted, want)
+	}
+}
@@ -0,0 +1,127 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// maxTokens 单次分类或学习最多使用的词数
+const maxTokens = 200
+
+// TokenCounts 词在垃圾内容和正常内容中出现的文档数
+type TokenCounts struct {
+	Spam int64
+	Ham  int64
+}
+
+// BayesStore 贝叶斯分类器的词频存储
+type BayesStore interface {
+	// Counts 返回各词的文档数以及垃圾、正常内容的总文档数
+	Counts(tokens []string) (counts map[string]TokenCounts, docs TokenCounts, err error)
+	// Learn 记录一篇文档的所有词
+	Learn(tokens []string, isSpam bool) error
+}
+
+// Bayes 朴素贝叶斯分类器，根据人工审核结果学习
+type Bayes struct {
+	store   BayesStore
+	minDocs int64
+}
+
+// NewBayes 创建贝叶斯分类器，两类样本都达到 minDocs 篇之前不参与判定
+func NewBayes(store BayesStore, minDocs int64) *Bayes {
+	return &Bayes{store: store, minDocs: minDocs}
+}
+
+// Name 检查器名称
+func (b *Bayes) Name() string {
+	return "bayes"
+}
+
+// Check 计算内容为垃圾内容的概率
+func (b *Bayes) Check(ctx context.Context, s *Submission) (Result, error) {
+	tokens := Tokenize(s.Content)
+	if len(tokens) == 0 {
+		return Result{}, nil
+	}
+
+	counts, docs, err := b.store.Counts(tokens)
+	if err != nil {
+		return Result{}, err
+	}
+	if docs.Spam < b.minDocs || docs.Ham < b.minDocs {
+		return Result{}, nil
+	}
+
+	// 使用对数概率避免下溢，词的条件概率做拉普拉斯平滑
+	logSpam := math.Log(float64(docs.Spam) / float64(docs.Spam+docs.Ham))
+	logHam := math.Log(float64(docs.Ham) / float64(docs.Spam+docs.Ham))
+	for _, token := range tokens {
+		c, ok := counts[token]
+		if !ok {
+			continue
+		}
+		logSpam += math.Log(float64(c.Spam+1) / float64(docs.Spam+2))
+		logHam += math.Log(float64(c.Ham+1) / float64(docs.Ham+2))
+	}
+
+	score := 1 / (1 + math.Exp(logHam-logSpam))
+	return Result{Score: score, Reason: fmt.Sprintf("垃圾内容概率 %.2f", score)}, nil
+}
+
+// Train 学习一篇已标记的内容
+func (b *Bayes) Train(ctx context.Context, s *Submission, isSpam bool) error {
+	tokens := Tokenize(s.Content)
+	if len(tokens) == 0 {
+		return nil
+	}
+	return b.store.Learn(tokens, isSpam)
+}
+
+// Tokenize 将文本切分为去重后的词：字母数字连续串按单词切分，汉字按相邻两字切分
+func Tokenize(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	add := func(token string) {
+		if len(tokens) < maxTokens && !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	var word, han []rune
+	flush := func() {
+		if n := len(word); n >= 2 && n <= 32 {
+			add(strings.ToLower(string(word)))
+		}
+		if len(han) == 1 {
+			add(string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			add(string(han[i : i+2]))
+		}
+		word, han = word[:0], han[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if len(word) > 0 {
+				flush()
+			}
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(han) > 0 {
+				flush()
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,84 @@
+package spam
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Blocklist 屏蔽词和正则表达式检查器，同时检查内容、作者名和邮箱
+type Blocklist struct {
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+// NewBlocklist 创建屏蔽词检查器，关键词不区分大小写
+func NewBlocklist(keywords, patterns []string) (*Blocklist, error) {
+	b := &Blocklist{}
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			b.keywords = append(b.keywords, k)
+		}
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("无效的屏蔽规则 %q: %w", p, err)
+		}
+		b.patterns = append(b.patterns, re)
+	}
+	return b, nil
+}
+
+// LoadBlocklist 从文件加载屏蔽词，每行一条，/.../ 包裹的行为正则表达式，# 开头的行为注释
+func LoadBlocklist(path string, keywords []string) (*Blocklist, error) {
+	var patterns []string
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			switch {
+			case line == "" || strings.HasPrefix(line, "#"):
+			case len(line) > 2 && strings.HasPrefix(line, "/") && strings.HasSuffix(line, "/"):
+				patterns = append(patterns, line[1:len(line)-1])
+			default:
+				keywords = append(keywords, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return NewBlocklist(keywords, patterns)
+}
+
+// Name 检查器名称
+func (b *Blocklist) Name() string {
+	return "blocklist"
+}
+
+// Check 命中任意屏蔽词或正则表达式即判定为垃圾内容
+func (b *Blocklist) Check(ctx context.Context, s *Submission) (Result, error) {
+	text := s.Content + "\n" + s.Author + "\n" + s.Email
+	lower := strings.ToLower(text)
+	for _, k := range b.keywords {
+		if strings.Contains(lower, k) {
+			return Result{Score: 1, Reason: "命中屏蔽词 " + k}, nil
+		}
+	}
+	for _, re := range b.patterns {
+		if re.MatchString(text) {
+			return Result{Score: 1, Reason: "命中屏蔽规则 " + re.String()}, nil
+		}
+	}
+	return Result{}, nil
+}
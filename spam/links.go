@@ -0,0 +1,37 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// linkPattern 匹配 http(s) 链接和以 www. 开头的网址
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://|\bwww\.`)
+
+// LinkChecker 根据链接数量判断垃圾内容
+type LinkChecker struct {
+	maxLinks int
+}
+
+// NewLinkChecker 创建链接数量检查器，链接数超过 maxLinks 时判定为垃圾内容
+func NewLinkChecker(maxLinks int) *LinkChecker {
+	return &LinkChecker{maxLinks: maxLinks}
+}
+
+// Name 检查器名称
+func (c *LinkChecker) Name() string {
+	return "links"
+}
+
+// Check 统计内容中的链接数量，达到上限时需要审核，超过上限时判定为垃圾内容
+func (c *LinkChecker) Check(ctx context.Context, s *Submission) (Result, error) {
+	n := len(linkPattern.FindAllStringIndex(s.Content, -1))
+	switch {
+	case n > c.maxLinks:
+		return Result{Score: 1, Reason: fmt.Sprintf("包含 %d 个链接", n)}, nil
+	case n > 0 && n == c.maxLinks:
+		return Result{Score: 0.5, Reason: fmt.Sprintf("包含 %d 个链接", n)}, nil
+	}
+	return Result{}, nil
+}
@@ -0,0 +1,115 @@
+package spam
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 提交内容类型
+const (
+	TypeComment = "comment"
+	TypeSignup  = "signup"
+)
+
+// 检查结论
+const (
+	ActionAllow  = "allow"  // 正常内容
+	ActionReview = "review" // 可疑内容，需要人工审核
+	ActionSpam   = "spam"   // 垃圾内容
+)
+
+// Submission 待检查的用户提交内容
+type Submission struct {
+	Type      string // comment 或 signup
+	Author    string
+	Email     string
+	Content   string
+	IP        string
+	UserAgent string
+}
+
+// Result 单个检查器的结果，Score 取值 0~1，越大越可能是垃圾内容
+type Result struct {
+	Score  float64
+	Reason string
+}
+
+// Verdict 检查流水线的综合结论
+type Verdict struct {
+	Action  string   `json:"action"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Checker 垃圾内容检查器接口
+type Checker interface {
+	// Name 检查器名称，用于日志
+	Name() string
+	// Check 检查提交内容
+	Check(ctx context.Context, s *Submission) (Result, error)
+}
+
+// Trainer 可以根据人工审核结果学习的检查器
+type Trainer interface {
+	// Train 将提交内容标记为垃圾内容或正常内容
+	Train(ctx context.Context, s *Submission, isSpam bool) error
+}
+
+// Pipeline 垃圾内容检查流水线，综合得分取所有检查器的最高分
+type Pipeline struct {
+	checkers        []Checker
+	spamThreshold   float64
+	reviewThreshold float64
+}
+
+// NewPipeline 创建检查流水线，得分达到 spamThreshold 判定为垃圾内容，达到 reviewThreshold 需要人工审核
+func NewPipeline(spamThreshold, reviewThreshold float64, checkers ...Checker) *Pipeline {
+	return &Pipeline{
+		checkers:        checkers,
+		spamThreshold:   spamThreshold,
+		reviewThreshold: reviewThreshold,
+	}
+}
+
+// Check 依次运行所有检查器，单个检查器出错时记录日志并跳过
+func (p *Pipeline) Check(ctx context.Context, s *Submission) Verdict {
+	verdict := Verdict{Action: ActionAllow}
+	for _, checker := range p.checkers {
+		result, err := checker.Check(ctx, s)
+		if err != nil {
+			logrus.Warnf("垃圾内容检查器 %s 出错: %v", checker.Name(), err)
+			continue
+		}
+		if result.Score <= 0 {
+			continue
+		}
+		if result.Reason != "" {
+			verdict.Reasons = append(verdict.Reasons, checker.Name()+": "+result.Reason)
+		}
+		if result.Score > verdict.Score {
+			verdict.Score = result.Score
+		}
+	}
+
+	switch {
+	case verdict.Score >= p.spamThreshold:
+		verdict.Action = ActionSpam
+	case verdict.Score >= p.reviewThreshold:
+		verdict.Action = ActionReview
+	}
+	return verdict
+}
+
+// Train 将人工审核结果提供给所有支持学习的检查器，出错时只记录日志
+func (p *Pipeline) Train(ctx context.Context, s *Submission, isSpam bool) {
+	for _, checker := range p.checkers {
+		trainer, ok := checker.(Trainer)
+		if !ok {
+			continue
+		}
+		if err := trainer.Train(ctx, s, isSpam); err != nil {
+			logrus.Warnf("垃圾内容检查器 %s 学习失败: %v", checker.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"blog-backend/config"
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage S3 兼容对象存储（AWS S3、MinIO、OSS、COS 等）
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3Storage 创建 S3 兼容存储实例
+func NewS3Storage(cfg *config.Config) (*S3Storage, error) {
+	if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+		return nil, errors.New("S3存储缺少endpoint或bucket配置")
+	}
+
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	publicURL := cfg.S3PublicURL
+	if publicURL == "" {
+		scheme := "http"
+		if cfg.S3UseSSL {
+			scheme = "https"
+		}
+		publicURL = scheme + "://" + cfg.S3Endpoint + "/" + cfg.S3Bucket
+	}
+
+	return &S3Storage{
+		client:    client,
+		bucket:    cfg.S3Bucket,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}, nil
+}
+
+// Put 上传文件
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Delete 删除文件
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// URL 返回文件的访问地址
+func (s *S3Storage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"blog-backend/config"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Storage 文件存储接口
+type Storage interface {
+	// Put 保存文件
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Delete 删除文件，文件不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 返回文件的访问地址
+	URL(key string) string
+}
+
+// NewStorage 根据配置创建存储实例
+func NewStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return NewLocalStorage(cfg.UploadDir, cfg.UploadBaseURL)
+	case "s3":
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", cfg.StorageDriver)
+	}
+}
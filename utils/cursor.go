@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Cursor 游标分页位置，按 (created_at, id) 定位
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"i"`
+	Before    bool      `json:"b,omitempty"` // true 表示取该位置之前（更新）的数据
+}
+
+// EncodeCursor 将游标编码为不透明字符串
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析游标字符串
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("无效的分页游标")
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, errors.New("无效的分页游标")
+	}
+	return &c, nil
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"blog-backend/config"
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,12 +12,13 @@ import (
 
 // Claims JWT声明
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID    uint   `json:"user_id"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken 生成JWT令牌
-func GenerateToken(userID uint, cfg *config.Config) (string, error) {
+func GenerateToken(userID uint, sessionID string, cfg *config.Config) (string, error) {
 	// 解析过期时间
 	expirationTime, err := time.ParseDuration(cfg.JWTExpiry)
 	if err != nil {
@@ -25,7 +28,8 @@ func GenerateToken(userID uint, cfg *config.Config) (string, error) {
 
 	// 创建声明
 	claims := &Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expirationTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -46,3 +50,55 @@ func GenerateToken(userID uint, cfg *config.Config) (string, error) {
 
 	return tokenString, nil
 }
+
+// UnlockClaims 密码保护文章的访问令牌声明
+type UnlockClaims struct {
+	PostID      uint   `json:"post_id"`
+	Fingerprint string `json:"fp"`
+	jwt.RegisteredClaims
+}
+
+// unlockTokenTTL 文章访问令牌有效期
+const unlockTokenTTL = 24 * time.Hour
+
+// GenerateUnlockToken 生成密码保护文章的访问令牌，令牌与当前密码绑定，修改密码后旧令牌失效
+func GenerateUnlockToken(postID uint, passwordHash string, cfg *config.Config) (string, error) {
+	claims := &UnlockClaims{
+		PostID:      postID,
+		Fingerprint: passwordFingerprint(passwordHash),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(unlockTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "blog-backend",
+			Subject:   "post-unlock",
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+	if err != nil {
+		logrus.Errorf("生成文章访问令牌错误: %v", err)
+		return "", err
+	}
+	return tokenString, nil
+}
+
+// ValidateUnlockToken 校验密码保护文章的访问令牌
+func ValidateUnlockToken(tokenString string, postID uint, passwordHash string, cfg *config.Config) bool {
+	if tokenString == "" {
+		return false
+	}
+	claims := &UnlockClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithSubject("post-unlock"))
+	if err != nil || !token.Valid {
+		return false
+	}
+	return claims.PostID == postID && claims.Fingerprint == passwordFingerprint(passwordHash)
+}
+
+// passwordFingerprint 密码哈希的摘要，避免在令牌中暴露哈希本身
+func passwordFingerprint(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"bytes"
+	stdhtml "html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sirupsen/logrus"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// postMarkdown 文章 Markdown 渲染器：CommonMark + GFM（表格、删除线、任务列表、自动链接）+ 脚注
+var postMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote),
+	// 允许原始 HTML，输出统一经过 postPolicy 过滤
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// commentMarkdown 评论 Markdown 渲染器：仅 CommonMark 及删除线、自动链接，不输出原始 HTML
+var commentMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.Strikethrough, extension.Linkify),
+)
+
+// postPolicy 文章 HTML 过滤策略，在 UGC 策略基础上保留代码高亮语言及脚注所需属性
+var postPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^fn(ref)?:\d+(:\d+)?$`)).OnElements("sup", "li")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnote(s|-ref|-backref)$`)).OnElements("a", "div")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).OnElements("a", "div")
+	p.AllowAttrs("type", "checked", "disabled").OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	return p
+}()
+
+// commentPolicy 评论 HTML 过滤策略，只保留基础的行内及块级格式
+var commentPolicy = func() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "em", "strong", "del", "code", "pre", "blockquote", "ul", "ol", "li")
+	p.AllowStandardURLs()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// RenderPostMarkdown 将文章 Markdown 渲染为过滤后的 HTML
+func RenderPostMarkdown(source string) string {
+	return renderMarkdown(postMarkdown, postPolicy, source)
+}
+
+// RenderCommentMarkdown 将评论 Markdown 渲染为过滤后的 HTML，只支持受限的语法子集
+func RenderCommentMarkdown(source string) string {
+	return renderMarkdown(commentMarkdown, commentPolicy, source)
+}
+
+// renderMarkdown 渲染 Markdown 并过滤 HTML
+func renderMarkdown(md goldmark.Markdown, policy *bluemonday.Policy, source string) string {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf); err != nil {
+		logrus.Errorf("渲染Markdown失败: %v", err)
+		return policy.Sanitize(source)
+	}
+	return policy.Sanitize(buf.String())
+}
+
+// excerptPolicy 去除所有标签，用于生成纯文本摘要
+var excerptPolicy = bluemonday.StrictPolicy()
+
+// Excerpt 从 HTML 中提取纯文本摘要，超过 maxRunes 个字符时截断
+func Excerpt(htmlContent string, maxRunes int) string {
+	text := stdhtml.UnescapeString(excerptPolicy.Sanitize(htmlContent))
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "…"
+}
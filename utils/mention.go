@@ -0,0 +1,25 @@
+package utils
+
+import "regexp"
+
+// maxMentions 单条内容最多解析的提及数
+const maxMentions = 20
+
+// mentionPattern 匹配 @用户名，@ 前不能是字母、数字、下划线或 @（排除邮箱地址）
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_-]{3,50})`)
+
+// ParseMentions 解析文本中提及的用户名，去重并保持出现顺序
+func ParseMentions(text string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		if len(usernames) >= maxMentions {
+			break
+		}
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			usernames = append(usernames, m[1])
+		}
+	}
+	return usernames
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"blog-backend/config"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords 常见及已泄露的弱密码集合
+var commonPasswords = func() map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// PasswordPolicy 密码策略
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool
+}
+
+// NewPasswordPolicy 根据配置创建密码策略
+func NewPasswordPolicy(cfg *config.Config) *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		MaxLength:     128,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+		RejectCommon:  cfg.PasswordRejectCommon,
+	}
+}
+
+// Validate 校验密码是否符合策略
+func (p *PasswordPolicy) Validate(password, username string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("密码长度不能少于%d位", p.MinLength)
+	}
+	if length > p.MaxLength {
+		return fmt.Errorf("密码长度不能超过%d位", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		return errors.New("密码必须包含大写字母")
+	}
+	if p.RequireLower && !hasLower {
+		return errors.New("密码必须包含小写字母")
+	}
+	if p.RequireDigit && !hasDigit {
+		return errors.New("密码必须包含数字")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return errors.New("密码必须包含特殊字符")
+	}
+
+	if p.RejectCommon {
+		lower := strings.ToLower(password)
+		if _, found := commonPasswords[lower]; found {
+			return errors.New("密码过于常见，请更换")
+		}
+		if username != "" && strings.Contains(lower, strings.ToLower(username)) {
+			return errors.New("密码不能包含用户名")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength slug 的最大长度
+const maxSlugLength = 80
+
+// Slugify 根据标题生成 slug，中文转换为不带声调的拼音
+func Slugify(title string) string {
+	var words []string
+	var word strings.Builder
+	var han []rune
+
+	flushWord := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	flushHan := func() {
+		if len(han) > 0 {
+			words = append(words, pinyin.LazyPinyin(string(han), pinyin.NewArgs())...)
+			han = han[:0]
+		}
+	}
+
+	// 先分解带音调的拉丁字母，去掉附加符号后保留基本字母
+	for _, r := range norm.NFKD.String(title) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			flushHan()
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+
+	slug := ""
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if len(slug)+len(w)+1 > maxSlugLength {
+			break
+		}
+		if slug != "" {
+			slug += "-"
+		}
+		slug += w
+	}
+
+	if slug == "" {
+		return "post"
+	}
+	return slug
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"blog-backend/config"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AbsoluteURL 将站内路径转换为绝对地址
+func AbsoluteURL(cfg *config.Config, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(cfg.SiteURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// PostURL 根据链接模板生成文章的绝对地址
+func PostURL(cfg *config.Config, id uint, slug string) string {
+	path := strings.NewReplacer(
+		"{id}", strconv.FormatUint(uint64(id), 10),
+		"{slug}", url.PathEscape(slug),
+	).Replace(cfg.PostPermalink)
+	return AbsoluteURL(cfg, path)
+}
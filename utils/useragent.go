@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// botPattern 常见爬虫、脚本和链接预览程序的 User-Agent 特征
+var botPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|archiver|fetch|scrape|curl|wget|python|java/|go-http-client|okhttp|axios|node-fetch|headless|phantomjs|lighthouse|pingdom|uptime|monitor|preview|facebookexternalhit|embedly|feed|rss`)
+
+// IsBot 判断请求是否来自爬虫或自动化程序，空 User-Agent 同样视为爬虫
+func IsBot(userAgent string) bool {
+	userAgent = strings.TrimSpace(userAgent)
+	return userAgent == "" || botPattern.MatchString(userAgent)
+}
+
+// ReferrerHost 提取来源地址的域名（去掉 www. 前缀），无法解析或为空时返回空字符串
+func ReferrerHost(referer string) string {
+	u, err := url.Parse(referer)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}